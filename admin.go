@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+
+	"seesharpsi/stixx_online/api"
+	"seesharpsi/stixx_online/backup"
+	"seesharpsi/stixx_online/janitor"
+)
+
+// Admin API under /api/v1/admin/, for whoever runs the server.
+// Requests send the admin token as "Authorization: Bearer <token>"; without a token the routes are disabled.
+
+// adminToken is the token the admin routes require, set by -admin-token
+var adminToken string
+
+// AdminGetJanitor reports the janitor's settings and its recent activity
+func AdminGetJanitor(w http.ResponseWriter, r *http.Request) {
+	if !checkAdmin(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, janitor.CurrentStatus())
+}
+
+// AdminRunJanitor cleans up idle games right away instead of waiting for the janitor's next run
+func AdminRunJanitor(w http.ResponseWriter, r *http.Request) {
+	if !checkAdmin(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, janitor.RunNow())
+}
+
+// AdminGetBackups lists the database snapshots along with the backup schedule
+func AdminGetBackups(w http.ResponseWriter, r *http.Request) {
+	if !checkAdmin(w, r) {
+		return
+	}
+
+	inventory, err := backup.CurrentInventory()
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, inventory)
+}
+
+// AdminCreateBackup snapshots the database right away
+func AdminCreateBackup(w http.ResponseWriter, r *http.Request) {
+	if !checkAdmin(w, r) {
+		return
+	}
+
+	snapshot, err := backup.TakeNow()
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, snapshot)
+}
+
+// AdminDownloadBackup sends a snapshot, for keeping a copy away from the server
+func AdminDownloadBackup(w http.ResponseWriter, r *http.Request) {
+	if !checkAdmin(w, r) {
+		return
+	}
+
+	name := r.PathValue("name")
+	path, err := backup.SnapshotPath(name)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	http.ServeFile(w, r, path)
+}
+
+// checkAdmin replies with an error unless the request carries the admin token
+func checkAdmin(w http.ResponseWriter, r *http.Request) bool {
+	log.Printf("got %s %s request\n", r.Method, r.URL.Path)
+
+	if adminToken == "" {
+		writeAPIError(w, http.StatusForbidden, api.CodeAdminDisabled, "admin routes are disabled, start the server with -admin-token")
+		return false
+	}
+
+	token, bearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !bearer || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		writeAPIError(w, http.StatusUnauthorized, api.CodeUnauthorized, "admin token required")
+		return false
+	}
+	return true
+}
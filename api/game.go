@@ -0,0 +1,82 @@
+package api
+
+// Move types
+const (
+	MoveWhite   = "white"
+	MoveColored = "colored"
+)
+
+// What a player did with the white sum of a roll, see PlayerSnapshot
+const (
+	WhiteMarked = "marked"
+	WhitePassed = "passed"
+)
+
+// Phases of the active player's turn after rolling, see Snapshot
+const (
+	PhaseWhite   = "white"   // deciding on the white sum
+	PhaseColored = "colored" // may mark a white die + colored die combination
+	PhaseDone    = "done"    // finished marking, waiting for the turn to end
+)
+
+// Reasons a game finishes
+const (
+	FinishTwoLocks      = "two_locks"
+	FinishFourPenalties = "four_penalties"
+	FinishAbandoned     = "abandoned" // nobody played for too long
+)
+
+// Snapshot is a game as one player sees it, for clients that don't render HTML
+type Snapshot struct {
+	Code            string           `json:"code"`
+	Status          string           `json:"status"` // "waiting", "active" or "finished"
+	FinishReason    string           `json:"finish_reason,omitempty"`
+	Version         int              `json:"version"`
+	SeedHash        string           `json:"seed_hash"` // commitment to the dice, see /verify/{code}
+	PlayerID        int              `json:"player_id"` // the player this snapshot was made for
+	CurrentPlayerID int              `json:"current_player_id"`
+	RollNumber      int              `json:"roll_number"`
+	Rolled          bool             `json:"rolled"`
+	Phase           string           `json:"phase"`
+	Dice            Dice             `json:"dice"`
+	Rows            []Row            `json:"rows"` // from top to bottom
+	Players         []PlayerSnapshot `json:"players"`
+	PossibleMoves   []Move           `json:"possible_moves"`
+}
+
+// PlayerSnapshot is one player's scoresheet in a Snapshot
+type PlayerSnapshot struct {
+	ID          int              `json:"id"`
+	Name        string           `json:"name"`
+	Marks       map[string][]int `json:"marks"`
+	Penalties   int              `json:"penalties"`
+	Score       int              `json:"score"`
+	WhiteAction string           `json:"white_action,omitempty"` // "marked" or "passed" once they decided on this roll's white sum
+}
+
+// Dice holds the values showing after a roll. The dice of locked rows are out of play and left out of Colored.
+type Dice struct {
+	White1  int            `json:"white1"`
+	White2  int            `json:"white2"`
+	Colored map[string]int `json:"colored"`
+}
+
+// WhiteSum returns the sum of the two white dice
+func (d Dice) WhiteSum() int {
+	return d.White1 + d.White2
+}
+
+// Row is one row of the scoresheet
+type Row struct {
+	Color   string `json:"color"`
+	Numbers []int  `json:"numbers"` // from left to right
+	Locked  bool   `json:"locked"`
+}
+
+// Move is a number a player can mark
+type Move struct {
+	PlayerID int    `json:"player_id"`
+	Color    string `json:"color"`
+	Number   int    `json:"number"`
+	Type     string `json:"type"` // "white" or "colored"
+}
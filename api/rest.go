@@ -0,0 +1,74 @@
+package api
+
+// Error codes returned by the JSON API and the game WebSocket.
+// The server maps the errors of its packages to them, so clients can tell failures apart by code.
+const (
+	CodeBadRequest        = "bad_request"
+	CodeUnauthorized      = "unauthorized"
+	CodeAdminDisabled     = "admin_disabled"
+	CodeNotHost           = "not_host"
+	CodeGameNotFound      = "game_not_found"
+	CodeConflict          = "conflict"
+	CodeAlreadyStarted    = "already_started"
+	CodeGameFull          = "game_full"
+	CodeBotSeat           = "bot_seat"
+	CodeUnknownStrategy   = "unknown_strategy"
+	CodeInvalidClientSeed = "invalid_client_seed"
+	CodeUnknownSnapshot   = "unknown_snapshot"
+	CodeBackupUnsupported = "backup_unsupported"
+	CodeTooFewPlayers     = "too_few_players"
+	CodeNotStarted        = "not_started"
+	CodeGameFinished      = "game_finished"
+	CodeNotYourTurn       = "not_your_turn"
+	CodeNotRolled         = "not_rolled"
+	CodeAlreadyRolled     = "already_rolled"
+	CodeUnknownPlayer     = "unknown_player"
+	CodeWhiteUsed         = "white_used"
+	CodeColoredUsed       = "colored_used"
+	CodeTurnDone          = "turn_done"
+	CodeWhiteFirst        = "white_first"
+	CodeNotActivePlayer   = "not_active_player"
+	CodeInvalidMove       = "invalid_move"
+	CodeUnknownMoveType   = "unknown_move_type"
+	CodeInternal          = "internal"
+)
+
+// ErrorResponse is the body of every failed API request
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+// Error explains why a request failed
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PlayerRequest names the player creating or joining a game.
+// The client seed is mixed into the game's dice. Send a random one: left out, it is derived from
+// public data and adds no randomness of the player's own.
+type PlayerRequest struct {
+	Name       string `json:"name"`
+	ClientSeed string `json:"client_seed,omitempty"`
+}
+
+// JoinResponse is returned after creating or joining a game.
+// Send the token as "Authorization: Bearer <token>" on later requests;
+// browsers get the same session as a cookie.
+type JoinResponse struct {
+	GameCode string `json:"game_code"`
+	PlayerID int    `json:"player_id"`
+	Token    string `json:"token"`
+}
+
+// MarkRequest marks a number using the white or colored dice
+type MarkRequest struct {
+	Color  string `json:"color"`
+	Number int    `json:"number"`
+	Type   string `json:"type"` // "white" or "colored"
+}
+
+// MovesResponse lists the moves a player can make right now
+type MovesResponse struct {
+	Moves []Move `json:"moves"`
+}
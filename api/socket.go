@@ -0,0 +1,58 @@
+// Package api describes the messages exchanged with clients that don't use the HTML pages.
+// It only depends on package events, so clients can use it without linking the server.
+package api
+
+import (
+	"seesharpsi/stixx_online/events"
+)
+
+// Messages a client sends over the game WebSocket at /ws/game/{gameCode}.
+//
+//	{"type": "roll"}
+//	{"type": "mark", "color": "red", "number": 5, "move": "white"}
+//	{"type": "pass"}
+//	{"type": "end_turn"}
+//	{"type": "snapshot"}
+//
+// Any message may carry an "id", which is echoed in the "ok" or "error" reply to it.
+const (
+	MsgRoll     = "roll"
+	MsgMark     = "mark"
+	MsgPass     = "pass"
+	MsgEndTurn  = "end_turn"
+	MsgSnapshot = "snapshot" // ask for a fresh snapshot
+)
+
+// Messages the server sends over the game WebSocket.
+//
+//	{"type": "snapshot", "seq": 12, "state": {...}}  the whole game, after connecting and after every batch of events
+//	{"type": "event", "seq": 13, "event": {...}}     something that happened in the game
+//	{"type": "ok", "id": "1"}                        an action succeeded
+//	{"type": "error", "id": "1", "code": "not_your_turn", "error": "..."}  an action was rejected
+const (
+	MsgEvent = "event"
+	MsgOK    = "ok"
+	MsgError = "error"
+)
+
+// ClientMessage is a message from a client over the game WebSocket
+type ClientMessage struct {
+	ID     string `json:"id,omitempty"`
+	Type   string `json:"type"`
+	Color  string `json:"color,omitempty"`  // row to mark
+	Number int    `json:"number,omitempty"` // number to mark
+	Move   string `json:"move,omitempty"`   // "white" or "colored" dice used for the mark
+}
+
+// ServerMessage is a message from the server over the game WebSocket.
+// Seq is the number of the last game event the message accounts for; clients
+// that reconnect pass the last one they saw as ?since= to receive what they missed.
+type ServerMessage struct {
+	Type  string        `json:"type"`
+	ID    string        `json:"id,omitempty"`
+	Seq   int           `json:"seq,omitempty"`
+	State *Snapshot     `json:"state,omitempty"`
+	Event *events.Event `json:"event,omitempty"`
+	Code  string        `json:"code,omitempty"` // one of the Code constants, for errors
+	Error string        `json:"error,omitempty"`
+}
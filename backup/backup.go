@@ -0,0 +1,273 @@
+// Package backup keeps rotating snapshots of the SQLite database, taken while the server runs.
+package backup
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"seesharpsi/stixx_online/db"
+)
+
+// Config says where snapshots go, how often they are taken and how many are kept
+type Config struct {
+	Dir      string
+	Interval time.Duration // time between scheduled snapshots, 0 to only take them when asked
+	Keep     int           // how many of the newest snapshots to keep, 0 keeps them all
+}
+
+// DefaultConfig is the configuration of the default schedule
+var DefaultConfig = Config{
+	Dir:      "./backups",
+	Interval: 24 * time.Hour,
+	Keep:     7,
+}
+
+// ErrUnknownSnapshot is returned when asking for a snapshot that doesn't exist
+var ErrUnknownSnapshot = errors.New("no such snapshot")
+
+// File is one snapshot of the database in the backup directory
+type File struct {
+	Name  string    `json:"name"` // file name in the backup directory
+	Size  int64     `json:"size"` // in bytes
+	Taken time.Time `json:"taken"`
+}
+
+// Snapshots are named after the time they were taken to the millisecond.
+// Older ones, named to the second, are still listed and restored.
+const timeFormat = "20060102-150405.000"
+
+var snapshotName = regexp.MustCompile(`^qwixx-(\d{8}-\d{6}(?:\.\d{3})?)\.db$`)
+
+// Source is a database that can copy itself while in use, such as Online
+type Source interface {
+	Backup(path string) error
+}
+
+// Take snapshots a database into dir, then deletes all but the newest keep snapshots
+func Take(source Source, dir string, keep int) (File, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return File{}, err
+	}
+
+	// A snapshot taken within the same millisecond as another one is named a millisecond later
+	taken := now().UTC().Truncate(time.Millisecond)
+	name := snapshotFile(taken)
+	for {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return File{}, err
+		}
+		taken = taken.Add(time.Millisecond)
+		name = snapshotFile(taken)
+	}
+
+	path := filepath.Join(dir, name)
+	err = source.Backup(path)
+	if err != nil {
+		return File{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Name: name, Size: info.Size(), Taken: taken}, rotate(dir, keep)
+}
+
+// now returns the current time, replaced in tests
+var now = time.Now
+
+func snapshotFile(taken time.Time) string {
+	return "qwixx-" + taken.Format(timeFormat) + ".db"
+}
+
+// List returns the snapshots in dir, newest first
+func List(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []File
+	for _, entry := range entries {
+		match := snapshotName.FindStringSubmatch(entry.Name())
+		if match == nil || !entry.Type().IsRegular() {
+			continue
+		}
+		taken, err := time.Parse("20060102-150405", match[1])
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, File{Name: entry.Name(), Size: info.Size(), Taken: taken})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Taken.After(snapshots[j].Taken) })
+	return snapshots, nil
+}
+
+// Path returns the file of a snapshot in dir, refusing names that aren't snapshots
+func Path(dir, name string) (string, error) {
+	if !snapshotName.MatchString(name) {
+		return "", ErrUnknownSnapshot
+	}
+	path := filepath.Join(dir, name)
+	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrUnknownSnapshot
+	}
+	return path, err
+}
+
+// rotate deletes all but the newest keep snapshots in dir
+func rotate(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	snapshots, err := List(dir)
+	if err != nil || len(snapshots) <= keep {
+		return err
+	}
+	for _, snapshot := range snapshots[keep:] {
+		err := os.Remove(filepath.Join(dir, snapshot.Name))
+		if err != nil {
+			return err
+		}
+		log.Printf("backup: deleted old snapshot %s\n", snapshot.Name)
+	}
+	return nil
+}
+
+// Inventory is the schedule's configuration and the snapshots it has
+type Inventory struct {
+	Running   bool   `json:"running"` // whether snapshots are taken on their own every interval
+	Dir       string `json:"dir"`
+	Interval  string `json:"interval"` // such as "24h0m0s"
+	Keep      int    `json:"keep"`
+	LastError string `json:"last_error,omitempty"` // why the last snapshot failed, if it did
+	Snapshots []File `json:"snapshots"`            // newest first
+}
+
+// Schedule takes snapshots of the default store every interval
+type Schedule struct {
+	config Config
+	takeMu sync.Mutex // held while taking a snapshot, so two never write at once
+
+	mu        sync.Mutex
+	running   bool
+	lastError string
+}
+
+func New(config Config) *Schedule {
+	return &Schedule{config: config}
+}
+
+// Default is the schedule used by the package level functions
+var Default = New(DefaultConfig)
+
+// Start takes a snapshot every interval in the background.
+// The first one is due an interval after the newest snapshot already in the directory, so
+// restarting the server doesn't rotate older snapshots away. Calling it again, or without an interval, does nothing.
+func (s *Schedule) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running || s.config.Interval <= 0 {
+		return
+	}
+	s.running = true
+
+	go func() {
+		for {
+			snapshots, err := List(s.config.Dir)
+			if err == nil && len(snapshots) > 0 {
+				time.Sleep(time.Until(snapshots[0].Taken.Add(s.config.Interval)))
+			}
+
+			_, err = s.Take()
+			if err != nil {
+				// Try again after an interval rather than right away
+				time.Sleep(s.config.Interval)
+			}
+		}
+	}()
+}
+
+// Take snapshots the default store now
+func (s *Schedule) Take() (File, error) {
+	s.takeMu.Lock()
+	defer s.takeMu.Unlock()
+
+	store, ok := db.Default.(*db.SQLStore)
+	if !ok {
+		return File{}, ErrUnsupported
+	}
+
+	snapshot, err := Take(Online{Store: store}, s.config.Dir, s.config.Keep)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.lastError = err.Error()
+		log.Printf("backup: snapshot failed: %s\n", err)
+		return snapshot, err
+	}
+	s.lastError = ""
+	log.Printf("backup: took snapshot %s (%d bytes)\n", snapshot.Name, snapshot.Size)
+	return snapshot, nil
+}
+
+// Inventory returns the schedule's configuration and the snapshots in its directory
+func (s *Schedule) Inventory() (Inventory, error) {
+	snapshots, err := List(s.config.Dir)
+	if err != nil {
+		return Inventory{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Inventory{
+		Running:   s.running,
+		Dir:       s.config.Dir,
+		Interval:  s.config.Interval.String(),
+		Keep:      s.config.Keep,
+		LastError: s.lastError,
+		Snapshots: append([]File{}, snapshots...),
+	}, nil
+}
+
+// Path returns the file of one of the schedule's snapshots
+func (s *Schedule) Path(name string) (string, error) {
+	return Path(s.config.Dir, name)
+}
+
+func Start() {
+	Default.Start()
+}
+
+func TakeNow() (File, error) {
+	return Default.Take()
+}
+
+func CurrentInventory() (Inventory, error) {
+	return Default.Inventory()
+}
+
+func SnapshotPath(name string) (string, error) {
+	return Default.Path(name)
+}
@@ -0,0 +1,152 @@
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"seesharpsi/stixx_online/db"
+)
+
+// fakeSource writes a small file instead of copying a database
+type fakeSource struct{}
+
+func (fakeSource) Backup(path string) error {
+	return os.WriteFile(path, []byte("snapshot"), 0o644)
+}
+
+// at makes Take see the given time until the test ends
+func at(t *testing.T, when time.Time) {
+	t.Helper()
+	saved := now
+	now = func() time.Time { return when }
+	t.Cleanup(func() { now = saved })
+}
+
+func TestTake(t *testing.T) {
+	dir := t.TempDir()
+	at(t, time.Date(2025, 3, 1, 12, 0, 0, 123456789, time.UTC))
+
+	first, err := Take(fakeSource{}, dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Name != "qwixx-20250301-120000.123.db" || first.Size != int64(len("snapshot")) {
+		t.Errorf("took %+v", first)
+	}
+
+	// Within the same millisecond
+	second, err := Take(fakeSource{}, dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Name != "qwixx-20250301-120000.124.db" {
+		t.Errorf("took %s after %s", second.Name, first.Name)
+	}
+
+	// Snapshots named to the second before names had milliseconds
+	err = os.WriteFile(filepath.Join(dir, "qwixx-20250301-110000.db"), nil, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Files that aren't snapshots
+	err = os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, snapshot := range snapshots {
+		names = append(names, snapshot.Name)
+	}
+	want := []string{second.Name, first.Name, "qwixx-20250301-110000.db"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] || names[2] != want[2] {
+		t.Errorf("listed %v, want %v", names, want)
+	}
+	if !snapshots[1].Taken.Equal(first.Taken) {
+		t.Errorf("listed %s as taken at %s, want %s", first.Name, snapshots[1].Taken, first.Taken)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var taken []File
+	for day := range 4 {
+		at(t, start.AddDate(0, 0, day))
+		snapshot, err := Take(fakeSource{}, dir, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		taken = append(taken, snapshot)
+	}
+
+	snapshots, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Name != taken[3].Name || snapshots[1].Name != taken[2].Name {
+		t.Errorf("kept %+v, want the last two of %+v", snapshots, taken)
+	}
+}
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+	snapshot, err := Take(fakeSource{}, dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := Path(dir, snapshot.Name)
+	if err != nil || path != filepath.Join(dir, snapshot.Name) {
+		t.Errorf("got %q, %v for %s", path, err, snapshot.Name)
+	}
+
+	for _, name := range []string{"qwixx-20250301-120000.999.db", "../qwixx.db", "notes.txt", ""} {
+		_, err := Path(dir, name)
+		if !errors.Is(err, ErrUnknownSnapshot) {
+			t.Errorf("got %v for %q, want ErrUnknownSnapshot", err, name)
+		}
+	}
+}
+
+func TestOnline(t *testing.T) {
+	dir := t.TempDir()
+	store, err := db.Open("sqlite", filepath.Join(dir, "qwixx.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	game, err := store.CreateGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := Take(Online{Store: store.(*db.SQLStore)}, filepath.Join(dir, "backups"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "backups", snapshot.Name)
+	err = db.CheckIntegrity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	copied, err := db.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer copied.Close()
+	_, err = copied.GetGame(game.GameCode)
+	if err != nil {
+		t.Errorf("the snapshot lacks the game: %s", err)
+	}
+}
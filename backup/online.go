@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+
+	"github.com/mattn/go-sqlite3"
+	"seesharpsi/stixx_online/db"
+)
+
+// ErrUnsupported is returned when backing up a store that isn't a SQLite database
+var ErrUnsupported = errors.New("only SQLite databases can be backed up here, use pg_dump for PostgreSQL")
+
+// Online copies a SQLite store with SQLite's online backup API, so the copy is
+// consistent even while games are being played
+type Online struct {
+	Store *db.SQLStore
+}
+
+// Backup copies the database to path. The copy is written next to path and renamed
+// into place once complete, so path never holds half a backup.
+func (o Online) Backup(path string) error {
+	if o.Store.Postgres() {
+		return ErrUnsupported
+	}
+
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	err := o.backupTo(tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (o Online) backupTo(path string) error {
+	dest, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	ctx := context.Background()
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+	srcConn, err := o.Store.DB().Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destRaw any) error {
+		return srcConn.Raw(func(srcRaw any) error {
+			backup, err := destRaw.(*sqlite3.SQLiteConn).Backup("main", srcRaw.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+
+			// Copy every page in one step: the database is small, and writes between steps
+			// would make the backup start over
+			_, err = backup.Step(-1)
+			if err != nil {
+				backup.Finish()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+}
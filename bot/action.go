@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"seesharpsi/stixx_online/game"
+)
+
+// Kinds of action a player can take
+const (
+	ActionRoll    = "roll"
+	ActionMark    = "mark"
+	ActionPass    = "pass"
+	ActionEndTurn = "end_turn" // done with the turn; it moves on once everyone has decided on the white sum
+)
+
+// Action is one thing a player does in a game
+type Action struct {
+	Kind     string
+	PlayerID int
+	Move     game.Move // the mark to make, for ActionMark
+}
+
+// Decide returns what a player using strategy does next in a game.
+// An active player who declines a colored move ends their turn, which only marks them done:
+// the others keep deciding on the white sum. It returns false while the player is waiting for someone else.
+func Decide(s game.State, playerID int, strategy Strategy) (Action, bool) {
+	if !s.Finished && !s.Rolled && s.ActivePlayer().ID == playerID {
+		return Action{Kind: ActionRoll, PlayerID: playerID}, true
+	}
+	if !deciding(s, playerID) {
+		return Action{}, false
+	}
+
+	move, ok := strategy.Choose(s, playerID, s.GetPossibleMoves(playerID))
+	_, decided := s.WhiteActions[playerID]
+	switch {
+	case ok:
+		return Action{Kind: ActionMark, PlayerID: playerID, Move: move}, true
+	case !decided:
+		return Action{Kind: ActionPass, PlayerID: playerID}, true
+	default:
+		return Action{Kind: ActionEndTurn, PlayerID: playerID}, true
+	}
+}
+
+// Apply returns the state after a mark, pass or end of turn, moving on to the next player
+// once everyone is done like the server does.
+// Rolls need dice, so they go through State.Roll instead.
+func (a Action) Apply(s game.State) (game.State, error) {
+	switch a.Kind {
+	case ActionMark:
+		next, err := s.ApplyMove(a.Move)
+		if err != nil {
+			return s, err
+		}
+		return next.EndTurnIfComplete()
+	case ActionPass:
+		next, err := s.PassWhite(a.PlayerID)
+		if err != nil {
+			return s, err
+		}
+		return next.EndTurnIfComplete()
+	case ActionEndTurn:
+		return s.EndTurn()
+	}
+	return s, game.ErrUnknownMoveType
+}
@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"testing"
+
+	"seesharpsi/stixx_online/dice"
+	"seesharpsi/stixx_online/game"
+)
+
+// TestTurnWaitsForWhiteSum plays bots against each other and checks that no turn ends
+// while a player other than the one acting still has to decide on the white sum
+func TestTurnWaitsForWhiteSum(t *testing.T) {
+	s := game.NewState([]game.PlayerState{{ID: 1}, {ID: 2}, {ID: 3}})
+	source := dice.Seeded{Seed: 1}
+
+	for s.RollNumber <= 30 && !s.Finished {
+		acted := false
+		for _, p := range s.Players {
+			action, ok := Decide(s, p.ID, Cautious{})
+			if !ok {
+				continue
+			}
+
+			if action.Kind == ActionRoll {
+				rolled, err := s.RollWith(source, 1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				s, err = s.Roll(rolled)
+				if err != nil {
+					t.Fatal(err)
+				}
+				acted = true
+				break
+			}
+
+			next, err := action.Apply(s)
+			if err != nil {
+				t.Fatalf("roll %d: player %d can't %s: %s", s.RollNumber, p.ID, action.Kind, err)
+			}
+			if !next.Rolled || next.Finished {
+				for _, other := range s.Players {
+					if _, decided := s.WhiteActions[other.ID]; !decided && other.ID != p.ID {
+						t.Errorf("roll %d: player %d's %s ended the turn before player %d decided on the white sum",
+							s.RollNumber, p.ID, action.Kind, other.ID)
+					}
+				}
+			}
+			s = next
+			acted = true
+			break
+		}
+
+		if !acted {
+			t.Fatalf("roll %d: no player can act", s.RollNumber)
+		}
+	}
+}
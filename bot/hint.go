@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"seesharpsi/stixx_online/game"
+)
+
+// Hint is one of a player's options right now, with its estimated effect on their final score
+type Hint struct {
+	Move    game.Move // the mark to make, unless Pass is set
+	Pass    bool      // making no mark: passing on the white sum, or ending the turn
+	Label   string
+	Value   float64
+	Reasons []string
+}
+
+// Hints ranks everything a player can do with the current roll, best first.
+// It returns nothing while the player has no decision to make.
+func Hints(s game.State, playerID int) []Hint {
+	if !deciding(s, playerID) {
+		return nil
+	}
+
+	var hints []Hint
+	for _, move := range s.GetPossibleMoves(playerID) {
+		hints = append(hints, moveHint(s, move))
+	}
+	hints = append(hints, passHint(s, playerID))
+
+	sort.SliceStable(hints, func(i, j int) bool { return hints[i].Value > hints[j].Value })
+	return hints
+}
+
+// deciding reports whether a player has to choose a mark, or none, with the current roll:
+// every player decides on the white sum, then the active player on the colored dice
+func deciding(s game.State, playerID int) bool {
+	if s.Finished || !s.Rolled {
+		return false
+	}
+	_, decided := s.WhiteActions[playerID]
+	active := s.ActivePlayer().ID == playerID
+	return !decided || (active && s.Phase == game.PhaseColored)
+}
+
+func moveHint(s game.State, move game.Move) Hint {
+	label := fmt.Sprintf("Mark %s %d with the white sum", move.Color, move.Number)
+	if move.Type == game.MoveColored {
+		label = fmt.Sprintf("Mark %s %d with a colored die", move.Color, move.Number)
+	}
+
+	var reasons []string
+	gain := Gain(s, move)
+	player, _ := s.Player(move.PlayerID)
+	count := len(player.Marks[move.Color]) + 1
+	if move.Number == s.Rows()[move.Color].LockNumber() {
+		reasons = append(reasons, fmt.Sprintf("locks the row, counting as an extra mark: %+d points now", gain))
+	} else {
+		reasons = append(reasons, fmt.Sprintf("%d marked in the row afterwards: %+d points now", count, gain))
+	}
+
+	if skipped := SkippedNumbers(s, move); len(skipped) > 0 {
+		numbers := make([]string, len(skipped))
+		for i, number := range skipped {
+			numbers[i] = fmt.Sprint(number)
+		}
+		reasons = append(reasons, fmt.Sprintf("gives up %s, about %.1f points of future chances", strings.Join(numbers, ", "), SkipCost(s, move)))
+	} else {
+		reasons = append(reasons, "skips no boxes")
+	}
+
+	if Lockable(s, move) {
+		reasons = append(reasons, "lets you lock the row later")
+	}
+
+	return Hint{Move: move, Label: label, Value: Value(s, move), Reasons: reasons}
+}
+
+func passHint(s game.State, playerID int) Hint {
+	hint := Hint{Pass: true, Label: "Pass on the white sum", Value: PassValue(s, playerID)}
+	if _, decided := s.WhiteActions[playerID]; decided {
+		hint.Label = "End your turn without a colored mark"
+	}
+
+	switch {
+	case PassPenalty(s, playerID):
+		hint.Reasons = []string{fmt.Sprintf("you marked nothing this turn, so it costs a penalty: %d points", -penaltyPoints)}
+	case s.ActivePlayer().ID == playerID && s.Phase == game.PhaseWhite:
+		hint.Reasons = []string{"keeps every box open, and you can still use a colored die"}
+	default:
+		hint.Reasons = []string{"keeps every box open"}
+	}
+	return hint
+}
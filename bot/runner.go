@@ -0,0 +1,201 @@
+package bot
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/events"
+	"seesharpsi/stixx_online/game"
+)
+
+// Delay is how long bots wait between their actions, so people can follow along
+var Delay = 700 * time.Millisecond
+
+// idleTimeout is how long a game can go without events before its bots stop watching it.
+// Play resumes them when something happens again through Drive, or on the next server start.
+const idleTimeout = 30 * time.Minute
+
+var (
+	drivingMu sync.Mutex
+	driving   = make(map[int]bool)
+)
+
+// Drive plays the bot seats of a started game in the background until it finishes.
+// Calling it again for a game whose bots are already playing does nothing.
+func Drive(gameID int) {
+	drivingMu.Lock()
+	defer drivingMu.Unlock()
+	if driving[gameID] {
+		return
+	}
+	driving[gameID] = true
+
+	go func() {
+		defer func() {
+			drivingMu.Lock()
+			delete(driving, gameID)
+			drivingMu.Unlock()
+		}()
+		drive(gameID)
+	}()
+}
+
+// DriveAll resumes the bots of every active game, for example after a restart
+func DriveAll() error {
+	gameIDs, err := db.GetBotGames()
+	if err != nil {
+		return err
+	}
+
+	for _, gameID := range gameIDs {
+		Drive(gameID)
+	}
+	return nil
+}
+
+func drive(gameID int) {
+	updates, unsubscribe := events.Subscribe(gameID)
+	defer unsubscribe()
+
+	var failures retries
+	for {
+		acted, done, err := Step(gameID)
+		if done {
+			return
+		}
+
+		wait, giveUp := failures.after(err)
+		if err != nil && !errors.Is(err, db.ErrConflict) {
+			log.Printf("bot error in game %d: %s\n", gameID, err)
+		}
+		if giveUp {
+			log.Printf("bots in game %d failed %d times in a row, waiting for the game to change\n", gameID, maxFailures)
+			failures = retries{}
+		} else if acted {
+			time.Sleep(wait)
+			continue
+		}
+
+		// Nothing for a bot to do until someone else acts
+		select {
+		case <-updates:
+			for len(updates) > 0 {
+				<-updates
+			}
+		case <-time.After(idleTimeout):
+			return
+		}
+	}
+}
+
+// maxFailures is how many times in a row a bot may fail the same way before its game's bots
+// stop retrying and wait for someone else to act
+const maxFailures = 5
+
+// retries tracks a bot action failing the same way again and again
+type retries struct {
+	err   string // the repeating error, empty after a success
+	count int
+}
+
+// after returns how long to wait after a bot action that ended in err, doubling Delay with every
+// repeat of the same error, and whether to give up retrying. Conflicts with other players count as successes.
+func (r *retries) after(err error) (time.Duration, bool) {
+	if err == nil || errors.Is(err, db.ErrConflict) {
+		*r = retries{}
+		return Delay, false
+	}
+
+	if err.Error() != r.err {
+		*r = retries{err: err.Error()}
+	}
+	r.count++
+	return Delay << r.count, r.count >= maxFailures
+}
+
+// Step makes one bot action in a game, if any bot can act.
+// It reports whether a bot tried to act and whether the game is over.
+func Step(gameID int) (bool, bool, error) {
+	gameData, err := db.GetGameByID(gameID)
+	if err != nil {
+		return false, true, err
+	}
+	if gameData.Status == "finished" {
+		return false, true, nil
+	}
+
+	players, err := db.GetPlayers(gameID)
+	if err != nil {
+		return false, false, err
+	}
+
+	s, err := game.LoadState(gameData)
+	if err != nil {
+		return false, false, err
+	}
+
+	for _, p := range players {
+		strategy, ok := Strategies[p.BotStrategy]
+		if !ok {
+			continue
+		}
+
+		action := Act(s, p.ID, strategy)
+		if action == nil {
+			continue
+		}
+		return true, false, action(gameID)
+	}
+
+	return false, false, nil
+}
+
+// Act returns what a bot player does next in a game, nil if it is waiting for someone else.
+// The returned function carries out the action through package game.
+func Act(s game.State, playerID int, strategy Strategy) func(gameID int) error {
+	action, ok := Decide(s, playerID, strategy)
+	if !ok {
+		return nil
+	}
+
+	return func(gameID int) error {
+		switch action.Kind {
+		case ActionRoll:
+			return game.RollDice(playerID, gameID)
+		case ActionMark:
+			move := action.Move
+			return game.MakeMark(playerID, move.Color, move.Number, gameID, move.Type)
+		case ActionPass:
+			return game.PassWhite(playerID, gameID)
+		default:
+			return game.EndTurn(playerID, gameID)
+		}
+	}
+}
+
+// ErrUnknownStrategy is returned when adding a bot with a strategy that doesn't exist
+var ErrUnknownStrategy = errors.New("unknown bot strategy")
+
+// Add seats a bot using the named strategy, on behalf of the game's creator
+func Add(hostID int, gameCode string, strategy string) (*db.Player, error) {
+	if _, ok := Strategies[strategy]; !ok {
+		return nil, ErrUnknownStrategy
+	}
+
+	gameData, err := db.GetGame(gameCode)
+	if err != nil {
+		return nil, err
+	}
+	players, err := db.GetPlayers(gameData.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(players) == 0 || players[0].ID != hostID {
+		return nil, game.ErrNotHost
+	}
+
+	return db.AddBot(gameCode, strategy, game.MaxPlayers)
+}
@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"seesharpsi/stixx_online/db"
+)
+
+func TestRetries(t *testing.T) {
+	broken := errors.New("disk full")
+	tests := []struct {
+		err    error
+		wait   int // in multiples of Delay
+		giveUp bool
+	}{
+		{nil, 1, false},
+		{broken, 2, false},
+		{broken, 4, false},
+		{fmt.Errorf("saving: %w", db.ErrConflict), 1, false},
+		{broken, 2, false},
+		{errors.New("other"), 2, false},
+		{broken, 2, false},
+		{broken, 4, false},
+		{broken, 8, false},
+		{broken, 16, false},
+		{broken, 32, true},
+	}
+
+	var r retries
+	for i, test := range tests {
+		wait, giveUp := r.after(test.err)
+		if wait != Delay*time.Duration(test.wait) || giveUp != test.giveUp {
+			t.Errorf("attempt %d with %v: waits %s and gives up %v, want %s and %v",
+				i+1, test.err, wait, giveUp, Delay*time.Duration(test.wait), test.giveUp)
+		}
+	}
+}
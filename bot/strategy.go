@@ -0,0 +1,99 @@
+// Package bot plays Qwixx for computer players.
+package bot
+
+import (
+	"sort"
+
+	"seesharpsi/stixx_online/game"
+)
+
+// Strategy decides which of a player's legal moves to make
+type Strategy interface {
+	// Name identifies the strategy, as stored for bot players
+	Name() string
+
+	// Choose picks one of moves, which are the player's legal moves from State.GetPossibleMoves.
+	// It returns false to make none of them: passing on the white sum, or ending the turn without a colored mark.
+	Choose(s game.State, playerID int, moves []game.Move) (game.Move, bool)
+}
+
+// Strategies lists the built-in strategies by name
+var Strategies = map[string]Strategy{
+	"greedy":   Greedy{},
+	"cautious": Cautious{},
+	"expected": ExpectedValue{},
+}
+
+// StrategyNames returns the names of the built-in strategies in alphabetical order
+func StrategyNames() []string {
+	names := make([]string, 0, len(Strategies))
+	for name := range Strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Greedy always marks something when it can, preferring the move that scores the most points now
+// and skips the fewest boxes.
+type Greedy struct{}
+
+func (Greedy) Name() string { return "greedy" }
+
+func (Greedy) Choose(s game.State, playerID int, moves []game.Move) (game.Move, bool) {
+	return best(moves, func(move game.Move) float64 {
+		return float64(Gain(s, move)) - 0.1*float64(Skipped(s, move))
+	})
+}
+
+// Cautious only marks numbers that skip no boxes, unless passing would cost it a penalty.
+type Cautious struct{}
+
+func (Cautious) Name() string { return "cautious" }
+
+func (Cautious) Choose(s game.State, playerID int, moves []game.Move) (game.Move, bool) {
+	var safe []game.Move
+	for _, move := range moves {
+		if Skipped(s, move) == 0 {
+			safe = append(safe, move)
+		}
+	}
+	if len(safe) > 0 {
+		return best(safe, func(move game.Move) float64 { return float64(Gain(s, move)) })
+	}
+
+	if PassPenalty(s, playerID) {
+		return best(moves, func(move game.Move) float64 { return -float64(Skipped(s, move)) })
+	}
+	return game.Move{}, false
+}
+
+// ExpectedValue weighs the points a move scores against the chance of marking the boxes it skips later
+// and of locking the row, and only marks when that beats passing.
+type ExpectedValue struct{}
+
+func (ExpectedValue) Name() string { return "expected" }
+
+func (ExpectedValue) Choose(s game.State, playerID int, moves []game.Move) (game.Move, bool) {
+	move, ok := best(moves, func(move game.Move) float64 { return Value(s, move) })
+	if !ok || Value(s, move) < PassValue(s, playerID) {
+		return game.Move{}, false
+	}
+	return move, true
+}
+
+// best returns the move with the highest score, the first one on ties
+func best(moves []game.Move, score func(game.Move) float64) (game.Move, bool) {
+	if len(moves) == 0 {
+		return game.Move{}, false
+	}
+
+	chosen := moves[0]
+	chosenScore := score(chosen)
+	for _, move := range moves[1:] {
+		if moveScore := score(move); moveScore > chosenScore {
+			chosen, chosenScore = move, moveScore
+		}
+	}
+	return chosen, true
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"seesharpsi/stixx_online/game"
+)
+
+// skipCost is how many points a skipped box is worth per unit of the chance of rolling it.
+// A box with the most likely sum, 7, costs 1/6 of that.
+const skipCost = 12.0
+
+// penaltyPoints is what a penalty costs at the end of the game
+const penaltyPoints = 5
+
+// lockMarks is how many marks a row needs before its lock number can be marked
+const lockMarks = 5
+
+// SumChance returns the probability of rolling a sum with two dice
+func SumChance(sum int) float64 {
+	if sum < 2 || sum > 12 {
+		return 0
+	}
+	ways := 6 - abs(sum-7)
+	return float64(ways) / 36
+}
+
+// Gain returns how many points a legal move adds to its player's score right away
+func Gain(s game.State, move game.Move) int {
+	next, err := s.ApplyMove(move)
+	if err != nil {
+		return 0
+	}
+	return next.Score(move.PlayerID) - s.Score(move.PlayerID)
+}
+
+// Skipped returns how many boxes of the row a move leaves behind unmarked
+func Skipped(s game.State, move game.Move) int {
+	return len(SkippedNumbers(s, move))
+}
+
+// SkippedNumbers returns the numbers of the row a move leaves behind unmarked
+func SkippedNumbers(s game.State, move game.Move) []int {
+	row := s.Rows()[move.Color]
+	player, _ := s.Player(move.PlayerID)
+
+	// Marks come sorted by number from the store, so on descending rows the last one isn't the rightmost
+	start := 0
+	for _, marked := range player.Marks[move.Color] {
+		if i := indexOf(row.Numbers, marked) + 1; i > start {
+			start = i
+		}
+	}
+
+	end := indexOf(row.Numbers, move.Number)
+	if end < start {
+		return nil
+	}
+	return row.Numbers[start:end]
+}
+
+// SkipCost estimates the points lost by giving up the boxes a move skips
+func SkipCost(s game.State, move game.Move) float64 {
+	cost := 0.0
+	for _, number := range SkippedNumbers(s, move) {
+		cost += SumChance(number) * skipCost
+	}
+	return cost
+}
+
+// Lockable reports whether a move gives its player the marks needed to lock the row later
+func Lockable(s game.State, move game.Move) bool {
+	player, _ := s.Player(move.PlayerID)
+	return len(player.Marks[move.Color])+1 == lockMarks && move.Number != s.Rows()[move.Color].LockNumber()
+}
+
+// LockValue estimates the points a move is worth through the chance of locking its row later
+func LockValue(s game.State, move game.Move) float64 {
+	if !Lockable(s, move) {
+		return 0
+	}
+	return SumChance(s.Rows()[move.Color].LockNumber()) * skipCost
+}
+
+// Value estimates how much a move changes its player's final score
+func Value(s game.State, move game.Move) float64 {
+	return float64(Gain(s, move)) - SkipCost(s, move) + LockValue(s, move)
+}
+
+// PassPenalty reports whether making no move now costs the player a penalty:
+// the active player is down to the colored dice and hasn't marked anything this turn.
+func PassPenalty(s game.State, playerID int) bool {
+	return s.ActivePlayer().ID == playerID && s.Phase == game.PhaseColored && !s.ActiveMarked()
+}
+
+// PassValue estimates how much making no move changes the player's final score
+func PassValue(s game.State, playerID int) float64 {
+	if PassPenalty(s, playerID) {
+		return -penaltyPoints
+	}
+	return 0
+}
+
+func indexOf(numbers []int, number int) int {
+	for i, n := range numbers {
+		if n == number {
+			return i
+		}
+	}
+	return -1
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
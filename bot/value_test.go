@@ -0,0 +1,37 @@
+package bot
+
+import (
+	"fmt"
+	"testing"
+
+	"seesharpsi/stixx_online/game"
+)
+
+func TestSkippedNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		color   string
+		marks   []int // in the order the store returns them: by number
+		number  int
+		skipped []int
+	}{
+		{"first mark", "red", nil, 4, []int{2, 3}},
+		{"next to the rightmost mark", "red", []int{3, 5}, 6, []int{}},
+		{"past the rightmost mark", "yellow", []int{2, 4}, 7, []int{5, 6}},
+		{"descending row", "green", []int{9, 12}, 8, []int{}},
+		{"descending row past the rightmost mark", "blue", []int{10, 12}, 6, []int{9, 8, 7}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := game.NewState([]game.PlayerState{{ID: 1}, {ID: 2}})
+			s.Players[0].Marks[test.color] = test.marks
+			move := game.Move{PlayerID: 1, Color: test.color, Number: test.number, Type: game.MoveWhite}
+
+			skipped := SkippedNumbers(s, move)
+			if fmt.Sprint(skipped) != fmt.Sprint(test.skipped) {
+				t.Errorf("skipped %v, want %v", skipped, test.skipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+// Package client plays Stixx Online games through the server's JSON API and WebSocket.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+
+	"seesharpsi/stixx_online/api"
+)
+
+// Client is one player's connection to a server.
+// Create or join a game first; every other method acts in that game as that player.
+type Client struct {
+	baseURL  string
+	http     *http.Client
+	gameCode string
+	playerID int
+	token    string
+}
+
+// Error is a request the server rejected.
+// It unwraps to the error below that matches its Code, so errors.Is(err, client.ErrNotYourTurn) works.
+type Error struct {
+	Status  int    // HTTP status
+	Code    string // one of the api.Code constants
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%s)", e.Message, e.Code)
+}
+
+func (e *Error) Unwrap() error {
+	return codeErrors[e.Code]
+}
+
+// Errors an *Error unwraps to, one for each api.Code constant
+var (
+	ErrBadRequest        = errors.New("bad request")
+	ErrUnauthorized      = errors.New("no session for this game")
+	ErrAdminDisabled     = errors.New("the admin API is disabled")
+	ErrNotHost           = errors.New("only the host can do that")
+	ErrGameNotFound      = errors.New("game not found")
+	ErrConflict          = errors.New("the game was updated by someone else")
+	ErrAlreadyStarted    = errors.New("game has already started")
+	ErrGameFull          = errors.New("game is full")
+	ErrBotSeat           = errors.New("a computer player has that name")
+	ErrUnknownStrategy   = errors.New("unknown bot strategy")
+	ErrInvalidClientSeed = errors.New("invalid client seed")
+	ErrUnknownSnapshot   = errors.New("unknown backup snapshot")
+	ErrBackupUnsupported = errors.New("backups are not supported by this server")
+	ErrTooFewPlayers     = errors.New("not enough players to start")
+	ErrNotStarted        = errors.New("game has not started")
+	ErrGameFinished      = errors.New("game is finished")
+	ErrNotYourTurn       = errors.New("not your turn")
+	ErrNotRolled         = errors.New("dice have not been rolled")
+	ErrAlreadyRolled     = errors.New("dice have already been rolled")
+	ErrUnknownPlayer     = errors.New("player is not in this game")
+	ErrWhiteUsed         = errors.New("already decided on the white sum")
+	ErrColoredUsed       = errors.New("already used a colored die")
+	ErrTurnDone          = errors.New("turn is already done")
+	ErrWhiteFirst        = errors.New("decide on the white sum first")
+	ErrNotActivePlayer   = errors.New("only the active player can do that")
+	ErrInvalidMove       = errors.New("invalid move")
+	ErrUnknownMoveType   = errors.New("unknown move type")
+	ErrInternal          = errors.New("internal server error")
+)
+
+var codeErrors = map[string]error{
+	api.CodeBadRequest:        ErrBadRequest,
+	api.CodeUnauthorized:      ErrUnauthorized,
+	api.CodeAdminDisabled:     ErrAdminDisabled,
+	api.CodeNotHost:           ErrNotHost,
+	api.CodeGameNotFound:      ErrGameNotFound,
+	api.CodeConflict:          ErrConflict,
+	api.CodeAlreadyStarted:    ErrAlreadyStarted,
+	api.CodeGameFull:          ErrGameFull,
+	api.CodeBotSeat:           ErrBotSeat,
+	api.CodeUnknownStrategy:   ErrUnknownStrategy,
+	api.CodeInvalidClientSeed: ErrInvalidClientSeed,
+	api.CodeUnknownSnapshot:   ErrUnknownSnapshot,
+	api.CodeBackupUnsupported: ErrBackupUnsupported,
+	api.CodeTooFewPlayers:     ErrTooFewPlayers,
+	api.CodeNotStarted:        ErrNotStarted,
+	api.CodeGameFinished:      ErrGameFinished,
+	api.CodeNotYourTurn:       ErrNotYourTurn,
+	api.CodeNotRolled:         ErrNotRolled,
+	api.CodeAlreadyRolled:     ErrAlreadyRolled,
+	api.CodeUnknownPlayer:     ErrUnknownPlayer,
+	api.CodeWhiteUsed:         ErrWhiteUsed,
+	api.CodeColoredUsed:       ErrColoredUsed,
+	api.CodeTurnDone:          ErrTurnDone,
+	api.CodeWhiteFirst:        ErrWhiteFirst,
+	api.CodeNotActivePlayer:   ErrNotActivePlayer,
+	api.CodeInvalidMove:       ErrInvalidMove,
+	api.CodeUnknownMoveType:   ErrUnknownMoveType,
+	api.CodeInternal:          ErrInternal,
+}
+
+// New returns a client for the server at baseURL, such as "http://localhost:9779"
+func New(baseURL string) (*Client, error) {
+	_, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Jar: jar},
+	}, nil
+}
+
+// GameCode returns the code of the game the client plays in
+func (c *Client) GameCode() string {
+	return c.gameCode
+}
+
+// PlayerID returns the ID of the client's player
+func (c *Client) PlayerID() int {
+	return c.playerID
+}
+
+// Token returns the session token, which Resume accepts to continue as the same player later
+func (c *Client) Token() string {
+	return c.token
+}
+
+// Resume continues a session started by CreateGame or JoinGame, possibly by another Client
+func (c *Client) Resume(gameCode string, playerID int, token string) {
+	c.gameCode = gameCode
+	c.playerID = playerID
+	c.token = token
+}
+
+// CreateGame creates a game and joins it as its host, with a random client seed
+func (c *Client) CreateGame(ctx context.Context, name string) error {
+	seed, err := newClientSeed()
+	if err != nil {
+		return err
+	}
+
+	var joined api.JoinResponse
+	err = c.do(ctx, "POST", "/api/v1/games", api.PlayerRequest{Name: name, ClientSeed: seed}, &joined)
+	if err != nil {
+		return err
+	}
+
+	c.Resume(joined.GameCode, joined.PlayerID, joined.Token)
+	return nil
+}
+
+// JoinGame joins a game with a random client seed, or rejoins it if a player with that name is already in it
+func (c *Client) JoinGame(ctx context.Context, gameCode string, name string) error {
+	seed, err := newClientSeed()
+	if err != nil {
+		return err
+	}
+
+	var joined api.JoinResponse
+	err = c.do(ctx, "POST", "/api/v1/games/"+url.PathEscape(gameCode)+"/players", api.PlayerRequest{Name: name, ClientSeed: seed}, &joined)
+	if err != nil {
+		return err
+	}
+
+	c.Resume(joined.GameCode, joined.PlayerID, joined.Token)
+	return nil
+}
+
+// newClientSeed returns a random client seed, so the game's dice get randomness the server doesn't control
+func newClientSeed() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Start starts the game and rolls the first dice. Only the host can start a game.
+func (c *Client) Start(ctx context.Context) (*api.Snapshot, error) {
+	return c.action(ctx, "POST", "/start", nil)
+}
+
+// State returns the game as the client's player sees it
+func (c *Client) State(ctx context.Context) (*api.Snapshot, error) {
+	return c.action(ctx, "GET", "", nil)
+}
+
+// Moves returns the moves the client's player can make right now
+func (c *Client) Moves(ctx context.Context) ([]api.Move, error) {
+	var moves api.MovesResponse
+	err := c.do(ctx, "GET", c.gamePath("/moves"), nil, &moves)
+	if err != nil {
+		return nil, err
+	}
+	return moves.Moves, nil
+}
+
+// Roll rolls the dice on the player's turn
+func (c *Client) Roll(ctx context.Context) (*api.Snapshot, error) {
+	return c.action(ctx, "POST", "/roll", nil)
+}
+
+// Mark marks a number. The move's PlayerID is ignored; the client always marks for its own player.
+func (c *Client) Mark(ctx context.Context, move api.Move) (*api.Snapshot, error) {
+	return c.action(ctx, "POST", "/marks", api.MarkRequest{Color: move.Color, Number: move.Number, Type: move.Type})
+}
+
+// Pass passes on the white sum of the current roll
+func (c *Client) Pass(ctx context.Context) (*api.Snapshot, error) {
+	return c.action(ctx, "POST", "/pass", nil)
+}
+
+// EndTurn finishes the player's turn, taking a penalty if they marked nothing.
+// The next player is up once everyone else has decided on the white sum.
+func (c *Client) EndTurn(ctx context.Context) (*api.Snapshot, error) {
+	return c.action(ctx, "POST", "/end-turn", nil)
+}
+
+func (c *Client) gamePath(suffix string) string {
+	return "/api/v1/games/" + url.PathEscape(c.gameCode) + suffix
+}
+
+// action sends a request about the client's game that answers with the game's state
+func (c *Client) action(ctx context.Context, method string, suffix string, body any) (*api.Snapshot, error) {
+	var snapshot api.Snapshot
+	err := c.do(ctx, method, c.gamePath(suffix), body, &snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// do sends a JSON request and decodes the response into out, turning error responses into *Error
+func (c *Client) do(ctx context.Context, method string, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return responseError(resp)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// responseError turns an error response of the server into an *Error
+func responseError(resp *http.Response) *Error {
+	var failed api.ErrorResponse
+	err := json.NewDecoder(resp.Body).Decode(&failed)
+	if err != nil || failed.Error.Code == "" {
+		return &Error{Status: resp.StatusCode, Code: api.CodeInternal, Message: resp.Status}
+	}
+	return &Error{Status: resp.StatusCode, Code: failed.Error.Code, Message: failed.Error.Message}
+}
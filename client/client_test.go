@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"seesharpsi/stixx_online/api"
+	"seesharpsi/stixx_online/events"
+)
+
+// fakeServer answers the requests of one game the way the real server does
+func fakeServer(t *testing.T) *httptest.Server {
+	var sockets atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/games", func(w http.ResponseWriter, r *http.Request) {
+		var req api.PlayerRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil || req.Name != "alice" {
+			writeJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: api.Error{Code: api.CodeBadRequest, Message: "name is required"}})
+			return
+		}
+		if len(req.ClientSeed) != 32 {
+			t.Errorf("created the game with client seed %q, want 32 random hex digits", req.ClientSeed)
+		}
+		writeJSON(w, http.StatusCreated, api.JoinResponse{GameCode: "ABC12", PlayerID: 1, Token: "secret"})
+	})
+	mux.HandleFunc("POST /api/v1/games/ABC12/start", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			writeJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: api.Error{Code: api.CodeUnauthorized, Message: "no session for this game"}})
+			return
+		}
+		writeJSON(w, http.StatusOK, api.Snapshot{Code: "ABC12", Status: "active", PlayerID: 1, Rolled: true})
+	})
+	mux.HandleFunc("POST /api/v1/games/ABC12/roll", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusConflict, api.ErrorResponse{Error: api.Error{Code: api.CodeNotYourTurn, Message: "it's not your turn"}})
+	})
+	mux.HandleFunc("GET /api/v1/games/ABC12/moves", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "proxy error", http.StatusBadGateway)
+	})
+	mux.HandleFunc("GET /ws/game/ABC12", func(w http.ResponseWriter, r *http.Request) {
+		// The first connection sees the game and is dropped; the session has expired by the time the client comes back
+		if sockets.Add(1) > 1 {
+			if r.URL.Query().Get("since") != "1" {
+				t.Errorf("reconnected with since=%q, want 1", r.URL.Query().Get("since"))
+			}
+			writeJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: api.Error{Code: api.CodeUnauthorized, Message: "no session for this game"}})
+			return
+		}
+
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.CloseNow()
+
+		ctx := r.Context()
+		wsjson.Write(ctx, conn, api.ServerMessage{Type: api.MsgSnapshot, State: &api.Snapshot{Code: "ABC12", Version: 1}})
+		wsjson.Write(ctx, conn, api.ServerMessage{Type: api.MsgEvent, Seq: 1, Event: &events.Event{Seq: 1, Type: events.Rolled}})
+		conn.Close(websocket.StatusGoingAway, "restarting")
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func newClient(t *testing.T) *Client {
+	c, err := New(fakeServer(t).URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.CreateGame(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestActions(t *testing.T) {
+	c := newClient(t)
+	if c.GameCode() != "ABC12" || c.PlayerID() != 1 || c.Token() != "secret" {
+		t.Fatalf("created game %s as player %d with token %q", c.GameCode(), c.PlayerID(), c.Token())
+	}
+
+	state, err := c.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Status != "active" || !state.Rolled {
+		t.Errorf("started game %+v", state)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	c := newClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		call   func() error
+		status int
+		code   string
+		is     error
+	}{
+		{"rule violation", func() error { _, err := c.Roll(ctx); return err }, http.StatusConflict, api.CodeNotYourTurn, ErrNotYourTurn},
+		{"bad request", func() error { return c.CreateGame(ctx, "") }, http.StatusBadRequest, api.CodeBadRequest, ErrBadRequest},
+		{"response that isn't JSON", func() error { _, err := c.Moves(ctx); return err }, http.StatusBadGateway, api.CodeInternal, ErrInternal},
+		{"missing session", func() error {
+			other, err := New(c.baseURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			other.Resume("ABC12", 1, "wrong")
+			_, err = other.Start(ctx)
+			return err
+		}, http.StatusUnauthorized, api.CodeUnauthorized, ErrUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.call()
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %v, want an *Error", err)
+			}
+			if apiErr.Status != test.status || apiErr.Code != test.code {
+				t.Errorf("got status %d and code %q, want %d and %q", apiErr.Status, apiErr.Code, test.status, test.code)
+			}
+			if !errors.Is(err, test.is) {
+				t.Errorf("%v is not %v", err, test.is)
+			}
+		})
+	}
+
+	for code, err := range codeErrors {
+		if !errors.Is(&Error{Code: code}, err) {
+			t.Errorf("code %q doesn't unwrap to %v", code, err)
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	c := newClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	updates, err := c.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Update
+	for update := range updates {
+		got = append(got, update)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("the subscription kept reconnecting after the server refused it")
+	}
+
+	if len(got) != 2 || got[0].State == nil || got[0].State.Version != 1 || got[1].Event == nil || got[1].Event.Type != events.Rolled {
+		t.Errorf("got updates %+v, want the state and then the roll", got)
+	}
+}
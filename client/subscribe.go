@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"seesharpsi/stixx_online/api"
+	"seesharpsi/stixx_online/events"
+)
+
+// reconnectDelay is how long Subscribe waits before reconnecting a dropped WebSocket
+const reconnectDelay = time.Second
+
+// Update is something that changed in the game: either an event or the whole new state
+type Update struct {
+	Event *events.Event
+	State *api.Snapshot
+}
+
+// Subscribe follows the game over the server's WebSocket.
+// The first update is the current state; after that every event is followed by the state it led to.
+// Dropped connections are reopened, receiving the events missed in between.
+// The channel is closed once ctx is done, or when the server refuses to reconnect, such as after the session expired.
+func (c *Client) Subscribe(ctx context.Context) (<-chan Update, error) {
+	conn, err := c.dial(ctx, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan Update)
+	go func() {
+		defer close(updates)
+
+		seq := -1
+		for {
+			seq = c.follow(ctx, conn, seq, updates)
+			conn.CloseNow()
+
+			// Keep trying until the server is back or we are told to stop
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(reconnectDelay):
+				}
+
+				conn, err = c.dial(ctx, seq)
+				if err == nil {
+					break
+				}
+
+				// Trying again won't help if the server turned us away
+				var rejected *Error
+				if errors.As(err, &rejected) && rejected.Status < 500 {
+					return
+				}
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// follow passes on the messages of one connection until it fails and returns the last event seen
+func (c *Client) follow(ctx context.Context, conn *websocket.Conn, seq int, updates chan<- Update) int {
+	for {
+		var msg api.ServerMessage
+		err := wsjson.Read(ctx, conn, &msg)
+		if err != nil {
+			return seq
+		}
+
+		var update Update
+		switch msg.Type {
+		case api.MsgEvent:
+			update.Event = msg.Event
+		case api.MsgSnapshot:
+			update.State = msg.State
+		default:
+			continue
+		}
+		if msg.Seq > seq {
+			seq = msg.Seq
+		}
+
+		select {
+		case updates <- update:
+		case <-ctx.Done():
+			return seq
+		}
+	}
+}
+
+// dial opens the game's WebSocket, asking for the events after since when it isn't negative
+func (c *Client) dial(ctx context.Context, since int) (*websocket.Conn, error) {
+	wsURL := strings.Replace(c.baseURL, "http", "ws", 1) + "/ws/game/" + url.PathEscape(c.gameCode)
+	if since >= 0 {
+		wsURL += "?since=" + strconv.Itoa(since)
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+c.token)
+
+	conn, resp, err := websocket.Dial(ctx, wsURL, &websocket.DialOptions{
+		HTTPClient: c.http,
+		HTTPHeader: header,
+	})
+	if err != nil && resp != nil && resp.StatusCode >= 400 {
+		return nil, responseError(resp)
+	}
+	return conn, err
+}
@@ -0,0 +1,162 @@
+// Command qwixx-db looks after the server's database.
+//
+//	qwixx-db status              show the schema version and any pending migrations
+//	qwixx-db migrate             apply pending migrations
+//	qwixx-db backup              snapshot the database into ./backups, keeping the newest 7
+//	qwixx-db check file          check that a snapshot is intact
+//	qwixx-db restore file        replace the database by a snapshot
+//	qwixx-db -db path status     use another SQLite database than ./qwixx.db
+//	qwixx-db -store postgres -db postgres://localhost/qwixx migrate
+//
+// The server applies pending migrations itself when it starts, so migrate is only
+// needed to upgrade a database ahead of time or to check that an upgrade works.
+//
+// backup is safe to run while the server is running. restore is not: stop the server first.
+// Both only work for SQLite; use pg_dump and pg_restore for PostgreSQL.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"seesharpsi/stixx_online/backup"
+	"seesharpsi/stixx_online/db"
+)
+
+func main() {
+	backend := flag.String("store", "sqlite", "database to use: sqlite or postgres")
+	dsn := flag.String("db", db.Path, "SQLite file or PostgreSQL connection string")
+	backupDir := flag.String("backup-dir", backup.DefaultConfig.Dir, "directory for snapshots")
+	keep := flag.Int("keep", backup.DefaultConfig.Keep, "how many of the newest snapshots to keep (0 keeps them all)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: qwixx-db [-store sqlite|postgres] [-db dsn] status|migrate")
+		fmt.Fprintln(os.Stderr, "       qwixx-db [-db path] [-backup-dir dir] [-keep n] backup")
+		fmt.Fprintln(os.Stderr, "       qwixx-db check|restore snapshot")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Commands working on snapshots rather than the open database
+	if flag.NArg() == 2 && *backend == "sqlite" {
+		var err error
+		switch flag.Arg(0) {
+		case "check":
+			err = check(flag.Arg(1))
+		case "restore":
+			err = restore(flag.Arg(1), *dsn)
+		default:
+			flag.Usage()
+			os.Exit(2)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var store *db.SQLStore
+	var err error
+	switch *backend {
+	case "sqlite":
+		store, err = db.NewSQLite(*dsn)
+	case "postgres":
+		store, err = db.NewPostgres(*dsn)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	switch flag.Arg(0) {
+	case "status":
+		err = status(store)
+	case "migrate":
+		err = migrate(store)
+	case "backup":
+		err = takeBackup(store, *backupDir, *keep)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// status prints the schema version and the migrations still to apply
+func status(store *db.SQLStore) error {
+	version, err := store.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	pending, err := store.PendingMigrations()
+	if err != nil {
+		return err
+	}
+
+	migrations := store.Migrations()
+	fmt.Printf("schema version %d of %d\n", version, migrations[len(migrations)-1].Version)
+	for _, m := range pending {
+		fmt.Printf("pending %d: %s\n", m.Version, m.Name)
+	}
+	return nil
+}
+
+// migrate applies the pending migrations and prints each one as it is applied
+func migrate(store *db.SQLStore) error {
+	applied, err := store.Migrate()
+	for _, m := range applied {
+		fmt.Printf("applied %d: %s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("nothing to migrate")
+	}
+	return status(store)
+}
+
+// takeBackup snapshots the database into dir and rotates out old snapshots
+func takeBackup(store *db.SQLStore, dir string, keep int) error {
+	file, err := backup.Take(backup.Online{Store: store}, dir, keep)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("backed up to %s (%d bytes)\n", filepath.Join(dir, file.Name), file.Size)
+	return nil
+}
+
+// check reports whether a snapshot could be restored
+func check(snapshot string) error {
+	err := db.CheckIntegrity(snapshot)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s is intact\n", snapshot)
+	return nil
+}
+
+// restore swaps a snapshot in for the database at path
+func restore(snapshot, path string) error {
+	err := db.Restore(snapshot, path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored %s to %s, the previous database is kept as %s.before-restore\n", snapshot, path, path)
+	return nil
+}
@@ -0,0 +1,89 @@
+// Command qwixx-sim plays bot strategies against each other in memory and reports how they did.
+//
+//	qwixx-sim -players greedy,expected -games 10000 -seed 42 -format json
+//
+// Every game uses the rules from package game and the strategies from package bot,
+// with seeded dice from package dice, so a run can be repeated exactly.
+// Nothing is stored. The seats take turns starting the game, so no strategy is
+// favored by always going first.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"seesharpsi/stixx_online/bot"
+	"seesharpsi/stixx_online/dice"
+	"seesharpsi/stixx_online/game"
+)
+
+func main() {
+	players := flag.String("players", "greedy,cautious,expected", "comma-separated strategies, one per seat")
+	games := flag.Int("games", 1000, "number of games to play")
+	seed := flag.Int64("seed", 1, "seed for the dice")
+	format := flag.String("format", "csv", "output format: csv or json")
+	flag.Parse()
+
+	seats, err := parseSeats(*players)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+	if *games < 1 {
+		fmt.Fprintln(os.Stderr, "-games must be at least 1")
+		os.Exit(2)
+	}
+
+	write := writeCSV
+	switch *format {
+	case "csv":
+	case "json":
+		write = writeJSON
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q, use csv or json\n", *format)
+		os.Exit(2)
+	}
+
+	source := dice.Seeded{Seed: *seed}
+	results := make([]result, 0, *games)
+	for i := 0; i < *games; i++ {
+		r, err := play(source, i+1, rotate(seats, i))
+		if err != nil {
+			log.Fatalf("game %d: %s", i+1, err)
+		}
+		results = append(results, r)
+	}
+
+	err = write(os.Stdout, summarize(*seed, seats, results))
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseSeats turns the -players flag into seats, allowing the same strategy more than once
+func parseSeats(list string) ([]seat, error) {
+	var seats []seat
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		strategy, ok := bot.Strategies[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown strategy %q, choose from %s", name, strings.Join(bot.StrategyNames(), ", "))
+		}
+		seats = append(seats, seat{number: len(seats) + 1, strategy: strategy})
+	}
+
+	if len(seats) < 2 || len(seats) > game.MaxPlayers {
+		return nil, fmt.Errorf("a game needs 2 to %d players, got %d", game.MaxPlayers, len(seats))
+	}
+	return seats, nil
+}
+
+// rotate returns the seats in turn order for the nth game, each seat starting in turn
+func rotate(seats []seat, n int) []seat {
+	k := n % len(seats)
+	return append(append([]seat(nil), seats[k:]...), seats[:k]...)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"seesharpsi/stixx_online/game"
+)
+
+// Report summarizes a tournament
+type Report struct {
+	Games         int                `json:"games"`
+	Seed          int64              `json:"seed"`
+	Rolls         Distribution       `json:"rolls"`          // game length in rolls
+	FinishReasons map[string]float64 `json:"finish_reasons"` // share of games ending for each reason
+	LockRates     map[string]float64 `json:"lock_rates"`     // share of games in which each row was locked
+	Seats         []SeatReport       `json:"seats"`
+}
+
+// SeatReport summarizes how one seat did over all games
+type SeatReport struct {
+	Seat      int          `json:"seat"`
+	Strategy  string       `json:"strategy"`
+	Wins      float64      `json:"wins"` // shared wins count as a fraction
+	WinRate   float64      `json:"win_rate"`
+	Score     Distribution `json:"score"`
+	Penalties float64      `json:"mean_penalties"`
+	Locks     float64      `json:"mean_locks"` // rows closed by this seat per game
+}
+
+// Distribution describes a list of values
+type Distribution struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	Min    int     `json:"min"`
+	P10    int     `json:"p10"`
+	P25    int     `json:"p25"`
+	Median int     `json:"median"`
+	P75    int     `json:"p75"`
+	P90    int     `json:"p90"`
+	Max    int     `json:"max"`
+}
+
+// summarize builds the report for finished games
+func summarize(seed int64, seats []seat, results []result) Report {
+	report := Report{
+		Games:         len(results),
+		Seed:          seed,
+		FinishReasons: make(map[string]float64),
+		LockRates:     make(map[string]float64),
+		Seats:         make([]SeatReport, len(seats)),
+	}
+	for _, color := range game.Colors {
+		report.LockRates[color] = 0
+	}
+
+	rolls := make([]int, 0, len(results))
+	scores := make([][]int, len(seats))
+	for i, st := range seats {
+		report.Seats[i] = SeatReport{Seat: st.number, Strategy: st.strategy.Name()}
+	}
+
+	for _, r := range results {
+		s := r.state
+		rolls = append(rolls, s.RollNumber)
+		report.FinishReasons[s.FinishReason]++
+		for color, locked := range s.Locked {
+			if locked {
+				report.LockRates[color]++
+			}
+		}
+
+		winners := winners(s)
+		rows := s.Rows()
+		for _, p := range s.Players {
+			seatReport := &report.Seats[p.ID-1]
+			scores[p.ID-1] = append(scores[p.ID-1], s.Score(p.ID))
+			seatReport.Penalties += float64(p.Penalties)
+			for color, marks := range p.Marks {
+				if rows[color].HasLockBonus(marks) {
+					seatReport.Locks++
+				}
+			}
+			if winners[p.ID] {
+				seatReport.Wins += 1 / float64(len(winners))
+			}
+		}
+	}
+
+	games := float64(len(results))
+	for reason := range report.FinishReasons {
+		report.FinishReasons[reason] /= games
+	}
+	for color := range report.LockRates {
+		report.LockRates[color] /= games
+	}
+	for i := range report.Seats {
+		seatReport := &report.Seats[i]
+		seatReport.WinRate = seatReport.Wins / games
+		seatReport.Penalties /= games
+		seatReport.Locks /= games
+		seatReport.Score = distribution(scores[i])
+	}
+	report.Rolls = distribution(rolls)
+
+	return report
+}
+
+// winners returns the IDs of the players with the highest score
+func winners(s game.State) map[int]bool {
+	top := math.MinInt
+	ids := make(map[int]bool)
+	for _, p := range s.Players {
+		score := s.Score(p.ID)
+		if score > top {
+			top = score
+			ids = make(map[int]bool)
+		}
+		if score == top {
+			ids[p.ID] = true
+		}
+	}
+	return ids
+}
+
+func distribution(values []int) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += float64(v)
+	}
+	mean := sum / float64(len(sorted))
+
+	variance := 0.0
+	for _, v := range sorted {
+		variance += (float64(v) - mean) * (float64(v) - mean)
+	}
+	variance /= float64(len(sorted))
+
+	percentile := func(p float64) int {
+		return sorted[int(p*float64(len(sorted)-1)+0.5)]
+	}
+
+	return Distribution{
+		Mean:   mean,
+		StdDev: math.Sqrt(variance),
+		Min:    sorted[0],
+		P10:    percentile(0.10),
+		P25:    percentile(0.25),
+		Median: percentile(0.50),
+		P75:    percentile(0.75),
+		P90:    percentile(0.90),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+func writeJSON(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// writeCSV writes the report as one row per statistic, with the seat or row it is about
+func writeCSV(w io.Writer, report Report) error {
+	out := csv.NewWriter(w)
+	records := [][]string{{"scope", "metric", "value"}}
+	add := func(scope, metric string, value float64) {
+		records = append(records, []string{scope, metric, strconv.FormatFloat(value, 'f', -1, 64)})
+	}
+	addDistribution := func(scope, metric string, d Distribution) {
+		add(scope, metric+"_mean", d.Mean)
+		add(scope, metric+"_stddev", d.StdDev)
+		add(scope, metric+"_min", float64(d.Min))
+		add(scope, metric+"_p10", float64(d.P10))
+		add(scope, metric+"_p25", float64(d.P25))
+		add(scope, metric+"_median", float64(d.Median))
+		add(scope, metric+"_p75", float64(d.P75))
+		add(scope, metric+"_p90", float64(d.P90))
+		add(scope, metric+"_max", float64(d.Max))
+	}
+
+	add("tournament", "games", float64(report.Games))
+	add("tournament", "seed", float64(report.Seed))
+	addDistribution("tournament", "rolls", report.Rolls)
+	for _, reason := range []string{game.FinishTwoLocks, game.FinishFourPenalties} {
+		add("tournament", "finish_"+reason, report.FinishReasons[reason])
+	}
+	for _, color := range game.Colors {
+		add("tournament", "locked_"+color, report.LockRates[color])
+	}
+
+	for _, seatReport := range report.Seats {
+		scope := "seat " + strconv.Itoa(seatReport.Seat) + " " + seatReport.Strategy
+		add(scope, "wins", seatReport.Wins)
+		add(scope, "win_rate", seatReport.WinRate)
+		addDistribution(scope, "score", seatReport.Score)
+		add(scope, "mean_penalties", seatReport.Penalties)
+		add(scope, "mean_locks", seatReport.Locks)
+	}
+
+	out.WriteAll(records)
+	return out.Error()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"seesharpsi/stixx_online/bot"
+	"seesharpsi/stixx_online/dice"
+	"seesharpsi/stixx_online/game"
+)
+
+// maxRolls stops a game that somehow never ends, which would mean a bug in a strategy or the rules
+const maxRolls = 1000
+
+var errStuck = errors.New("no player can act")
+
+// seat is one player of the tournament, keeping its strategy across games
+type seat struct {
+	number   int // 1-based, used as the player ID
+	strategy bot.Strategy
+}
+
+// result is the outcome of one simulated game
+type result struct {
+	state game.State
+	seats []seat // in turn order
+}
+
+// play runs one game to its end, with the seats taking turns in the given order
+func play(source dice.Dice, gameID int, seats []seat) (result, error) {
+	players := make([]game.PlayerState, len(seats))
+	for i, st := range seats {
+		players[i] = game.PlayerState{ID: st.number, Name: st.strategy.Name()}
+	}
+	s := game.NewState(players)
+
+	for !s.Finished {
+		if s.RollNumber > maxRolls {
+			return result{}, fmt.Errorf("game still running after %d rolls", maxRolls)
+		}
+
+		var err error
+		s, err = step(source, gameID, s, seats)
+		if err != nil {
+			return result{}, err
+		}
+	}
+
+	return result{state: s, seats: seats}, nil
+}
+
+// step lets the first player in turn order who has something to do act once
+func step(source dice.Dice, gameID int, s game.State, seats []seat) (game.State, error) {
+	for _, st := range seats {
+		action, ok := bot.Decide(s, st.number, st.strategy)
+		if !ok {
+			continue
+		}
+
+		if action.Kind == bot.ActionRoll {
+			rolled, err := s.RollWith(source, gameID)
+			if err != nil {
+				return s, err
+			}
+			return s.Roll(rolled)
+		}
+		return action.Apply(s)
+	}
+	return s, errStuck
+}
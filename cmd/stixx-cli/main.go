@@ -0,0 +1,131 @@
+// Command stixx-cli plays a game on a running server from the terminal.
+//
+//	stixx-cli -name alice -create
+//	stixx-cli -name bob -game ABC12
+//
+// The board is redrawn whenever the game changes. Type a command and press enter:
+// the number of a move to mark it, r to roll, p to pass on the white sum, e to end
+// your turn, s to start the game and q to quit.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"seesharpsi/stixx_online/api"
+	"seesharpsi/stixx_online/client"
+)
+
+func main() {
+	server := flag.String("server", "http://localhost:9779", "address of the server")
+	gameCode := flag.String("game", "", "code of the game to join")
+	name := flag.String("name", "", "your name in the game")
+	create := flag.Bool("create", false, "create a new game instead of joining one")
+	flag.Parse()
+
+	if *name == "" || (*gameCode == "") == !*create {
+		fmt.Fprintln(os.Stderr, "usage: stixx-cli -name NAME (-game CODE | -create)")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := client.New(*server)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *create {
+		err = c.CreateGame(ctx, *name)
+	} else {
+		err = c.JoinGame(ctx, strings.ToUpper(*gameCode), *name)
+	}
+	if err != nil {
+		log.Fatal("Failed to join game: ", err)
+	}
+
+	updates, err := c.Subscribe(ctx)
+	if err != nil {
+		log.Fatal("Failed to follow game: ", err)
+	}
+
+	commands := make(chan string)
+	go readCommands(commands)
+
+	var state *api.Snapshot
+	message := "Joined game " + c.GameCode()
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			if update.State == nil {
+				continue
+			}
+			state = update.State
+		case command, ok := <-commands:
+			if !ok || command == "q" {
+				return
+			}
+			if state == nil {
+				continue
+			}
+			message = run(ctx, c, state, command)
+		}
+
+		if state != nil {
+			fmt.Print(render(state, message))
+		}
+	}
+}
+
+// readCommands passes on each line typed by the player
+func readCommands(commands chan<- string) {
+	defer close(commands)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		commands <- strings.ToLower(strings.TrimSpace(scanner.Text()))
+	}
+}
+
+// run carries out a command and returns the message to show the player.
+// The board itself is updated by the subscription.
+func run(ctx context.Context, c *client.Client, state *api.Snapshot, command string) string {
+	var err error
+	switch command {
+	case "":
+		return ""
+	case "r":
+		_, err = c.Roll(ctx)
+	case "p":
+		_, err = c.Pass(ctx)
+	case "e":
+		_, err = c.EndTurn(ctx)
+	case "s":
+		_, err = c.Start(ctx)
+	default:
+		n, convErr := strconv.Atoi(command)
+		if convErr != nil || n < 1 || n > len(state.PossibleMoves) {
+			return "Unknown command " + strconv.Quote(command)
+		}
+		move := state.PossibleMoves[n-1]
+		_, err = c.Mark(ctx, move)
+		if err == nil {
+			return fmt.Sprintf("Marked %s %d", move.Color, move.Number)
+		}
+	}
+
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	return ""
+}
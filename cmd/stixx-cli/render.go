@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"seesharpsi/stixx_online/api"
+)
+
+// ANSI escape codes
+const (
+	clearScreen = "\033[H\033[2J"
+	reset       = "\033[0m"
+	bold        = "\033[1m"
+	dim         = "\033[2m"
+	reverse     = "\033[7m"
+)
+
+var colorCodes = map[string]string{
+	"red":    "\033[31m",
+	"yellow": "\033[33m",
+	"green":  "\033[32m",
+	"blue":   "\033[34m",
+	"white":  "\033[37m",
+}
+
+// render draws the whole screen for a game state
+func render(s *api.Snapshot, message string) string {
+	var b strings.Builder
+	b.WriteString(clearScreen)
+
+	fmt.Fprintf(&b, "%sQwixx%s  game %s%s%s  %s\n\n", bold, reset, bold, s.Code, reset, status(s))
+
+	me := player(s, s.PlayerID)
+	active := player(s, s.CurrentPlayerID)
+
+	// Dice
+	if s.Rolled {
+		fmt.Fprintf(&b, "Dice  %s  %s", die("white", s.Dice.White1), die("white", s.Dice.White2))
+		for _, row := range s.Rows {
+			if value, ok := s.Dice.Colored[row.Color]; ok {
+				fmt.Fprintf(&b, "  %s", die(row.Color, value))
+			}
+		}
+		fmt.Fprintf(&b, "   white sum %s%d%s\n\n", bold, s.Dice.White1+s.Dice.White2, reset)
+	} else if s.Status == "active" {
+		fmt.Fprintf(&b, "Waiting for %s to roll\n\n", active.Name)
+	}
+
+	// Your scoresheet
+	for _, row := range s.Rows {
+		b.WriteString(renderRow(row, me.Marks[row.Color], s.PossibleMoves))
+	}
+	fmt.Fprintf(&b, "Penalties %s\n\n", strings.Repeat("X ", me.Penalties)+strings.Repeat(". ", 4-min(me.Penalties, 4)))
+
+	// Everyone's scores
+	for _, p := range s.Players {
+		marker := "  "
+		if p.ID == s.CurrentPlayerID && s.Status == "active" {
+			marker = "> "
+		}
+		name := p.Name
+		if p.ID == s.PlayerID {
+			name += " (you)"
+		}
+		fmt.Fprintf(&b, "%s%-16s %4d points  %d penalties  %s\n", marker, name, p.Score, p.Penalties, p.WhiteAction)
+	}
+	b.WriteString("\n")
+
+	// What the player can do
+	for i, move := range s.PossibleMoves {
+		fmt.Fprintf(&b, "%2d) %s%s %d%s using %s dice\n", i+1, colorCodes[move.Color], move.Color, move.Number, reset, move.Type)
+	}
+	b.WriteString("\n" + commands(s) + "\n")
+
+	if message != "" {
+		b.WriteString(message + "\n")
+	}
+	b.WriteString("> ")
+	return b.String()
+}
+
+// renderRow draws one row of a scoresheet, marking crossed numbers and highlighting playable ones
+func renderRow(row api.Row, marks []int, moves []api.Move) string {
+	var b strings.Builder
+	color := colorCodes[row.Color]
+	fmt.Fprintf(&b, "%s%-7s%s", color, row.Color, reset)
+
+	marked := make(map[int]bool)
+	for _, number := range marks {
+		marked[number] = true
+	}
+	playable := make(map[int]bool)
+	for _, move := range moves {
+		if move.Color == row.Color {
+			playable[move.Number] = true
+		}
+	}
+
+	for _, number := range row.Numbers {
+		switch {
+		case marked[number]:
+			fmt.Fprintf(&b, " %s%s X%s", color, reverse, reset)
+		case playable[number]:
+			fmt.Fprintf(&b, " %s%s%2d%s", bold, color, number, reset)
+		default:
+			fmt.Fprintf(&b, " %s%2d%s", dim, number, reset)
+		}
+	}
+
+	if row.Locked {
+		fmt.Fprintf(&b, "  %slocked%s", bold, reset)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func die(color string, value int) string {
+	return fmt.Sprintf("%s%s %d %s", colorCodes[color], reverse, value, reset)
+}
+
+func status(s *api.Snapshot) string {
+	switch s.Status {
+	case "waiting":
+		return "waiting for players"
+	case "finished":
+		switch s.FinishReason {
+		case api.FinishTwoLocks:
+			return "game over: two rows are locked"
+		case api.FinishFourPenalties:
+			return "game over: a player took four penalties"
+		case api.FinishAbandoned:
+			return "game over: nobody played for too long"
+		}
+		return "game over"
+	}
+	return fmt.Sprintf("roll %d", s.RollNumber)
+}
+
+// commands lists the commands that make sense right now
+func commands(s *api.Snapshot) string {
+	var list []string
+	if len(s.PossibleMoves) > 0 {
+		list = append(list, "number: mark")
+	}
+
+	switch s.Status {
+	case "waiting":
+		if len(s.Players) > 0 && s.Players[0].ID == s.PlayerID {
+			list = append(list, "s: start")
+		}
+	case "active":
+		myTurn := s.CurrentPlayerID == s.PlayerID
+		if myTurn && !s.Rolled {
+			list = append(list, "r: roll")
+		}
+		if s.Rolled && player(s, s.PlayerID).WhiteAction == "" {
+			list = append(list, "p: pass on the white sum")
+		}
+		if myTurn && s.Rolled && s.Phase != api.PhaseDone {
+			list = append(list, "e: end turn")
+		}
+	}
+
+	list = append(list, "q: quit")
+	return strings.Join(list, "   ")
+}
+
+func player(s *api.Snapshot, id int) api.PlayerSnapshot {
+	for _, p := range s.Players {
+		if p.ID == id {
+			return p
+		}
+	}
+	return api.PlayerSnapshot{}
+}
@@ -0,0 +1,191 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"seesharpsi/stixx_online/events"
+)
+
+// ArchivedGame is the summary kept of a finished game once it is archived.
+// The game itself, with its rolls, is removed. Its event log is kept with the summary, so the game can still be replayed.
+type ArchivedGame struct {
+	ID           int
+	GameCode     string
+	CreatedAt    time.Time
+	FinishedAt   time.Time // when the game's last event happened
+	FinishReason string
+	Rolls        int
+	ArchivedAt   time.Time
+	Players      []ArchivedPlayer // in turn order
+	Events       []events.Event   // the game's log, see GetGameEvents
+}
+
+// ArchivedPlayer is how a player of an archived game ended up
+type ArchivedPlayer struct {
+	Name        string
+	TurnOrder   int
+	BotStrategy string
+	Penalties   int
+	Score       int
+	Marks       map[string][]int // numbers marked in each row
+}
+
+func (s *SQLStore) GetIdleGames(status string, idleSince time.Time) ([]Game, error) {
+	rows, err := s.db.Query(s.q(`
+		SELECT `+gameColumns+` FROM games
+		WHERE status = ?
+		  AND COALESCE((SELECT MAX(created_at) FROM game_events WHERE game_id = games.id), created_at) < ?
+		ORDER BY id
+	`), status, idleSince.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []Game
+	for rows.Next() {
+		game, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, *game)
+	}
+
+	return games, rows.Err()
+}
+
+func (s *SQLStore) ExpireGame(gameID int) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		game, err := scanGame(tx.QueryRow(s.q("SELECT "+gameColumns+" FROM games WHERE id = ?"+s.forUpdate()), gameID))
+		if err != nil {
+			return err
+		}
+		if game.Status != "waiting" {
+			return ErrAlreadyStarted
+		}
+		return s.deleteGame(tx, gameID)
+	})
+}
+
+func (s *SQLStore) ArchiveGame(gameID int, players []ArchivedPlayer) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		game, err := scanGame(tx.QueryRow(s.q("SELECT "+gameColumns+" FROM games WHERE id = ?"+s.forUpdate()), gameID))
+		if err != nil {
+			return err
+		}
+		if game.Status != "finished" {
+			return ErrNotFinished
+		}
+
+		evs, err := s.gameEvents(tx, gameID)
+		if err != nil {
+			return err
+		}
+		eventLog, err := json.Marshal(evs)
+		if err != nil {
+			return err
+		}
+
+		var archivedID int
+		err = tx.QueryRow(s.q(`
+			INSERT INTO archived_games (game_code, created_at, finished_at, finish_reason, rolls, archived_at, events)
+			SELECT game_code, created_at,
+			       COALESCE((SELECT MAX(created_at) FROM game_events WHERE game_id = games.id), created_at),
+			       finish_reason, roll_number, ?, ?
+			FROM games WHERE id = ?
+			RETURNING id
+		`), now(), string(eventLog), gameID).Scan(&archivedID)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range players {
+			marks, err := json.Marshal(p.Marks)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(s.q(`
+				INSERT INTO archived_players (archived_game_id, name, turn_order, bot_strategy, penalties, score, marks)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+			`), archivedID, p.Name, p.TurnOrder, p.BotStrategy, p.Penalties, p.Score, string(marks))
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.deleteGame(tx, gameID)
+	})
+}
+
+// deleteGame removes a game with everything belonging to it.
+// SQLite doesn't enforce the foreign keys here, so nothing is left to cascading deletes.
+func (s *SQLStore) deleteGame(tx *sql.Tx, gameID int) error {
+	for _, query := range []string{
+		"DELETE FROM sessions WHERE player_id IN (SELECT id FROM players WHERE game_id = ?)",
+		"DELETE FROM player_marks WHERE player_id IN (SELECT id FROM players WHERE game_id = ?)",
+		"DELETE FROM white_actions WHERE game_id = ?",
+		"DELETE FROM rolls WHERE game_id = ?",
+		"DELETE FROM game_events WHERE game_id = ?",
+		"DELETE FROM players WHERE game_id = ?",
+		"DELETE FROM games WHERE id = ?",
+	} {
+		_, err := tx.Exec(s.q(query), gameID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLStore) GetArchivedGame(gameCode string) (*ArchivedGame, error) {
+	archived := &ArchivedGame{}
+	var eventLog string
+	err := s.db.QueryRow(s.q(`
+		SELECT id, game_code, created_at, finished_at, finish_reason, rolls, archived_at, events
+		FROM archived_games WHERE game_code = ?
+	`), gameCode).Scan(
+		&archived.ID, &archived.GameCode, &archived.CreatedAt, &archived.FinishedAt,
+		&archived.FinishReason, &archived.Rolls, &archived.ArchivedAt, &eventLog,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Games archived before the log was kept have none
+	if eventLog != "" {
+		err = json.Unmarshal([]byte(eventLog), &archived.Events)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.db.Query(s.q(`
+		SELECT name, turn_order, bot_strategy, penalties, score, marks
+		FROM archived_players WHERE archived_game_id = ? ORDER BY turn_order
+	`), archived.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p ArchivedPlayer
+		var marks string
+		err := rows.Scan(&p.Name, &p.TurnOrder, &p.BotStrategy, &p.Penalties, &p.Score, &marks)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal([]byte(marks), &p.Marks)
+		if err != nil {
+			return nil, err
+		}
+		archived.Players = append(archived.Players, p)
+	}
+
+	return archived, rows.Err()
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// CheckIntegrity makes sure the file at path is an intact SQLite database this server can use
+func CheckIntegrity(path string) error {
+	_, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	conn, err := sql.Open("sqlite3", "file:"+url.PathEscape(path)+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rows, err := conn.Query("PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("%s is not a SQLite database: %w", path, err)
+	}
+	var problems []string
+	for rows.Next() {
+		var problem string
+		err := rows.Scan(&problem)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		problems = append(problems, problem)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return rows.Err()
+	}
+	if len(problems) != 1 || problems[0] != "ok" {
+		return fmt.Errorf("%s is damaged: %s", path, strings.Join(problems, "; "))
+	}
+
+	var version int
+	err = conn.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return fmt.Errorf("%s is not a game database: %w", path, err)
+	}
+	if latest := sqliteMigrations[len(sqliteMigrations)-1].Version; version > latest {
+		return fmt.Errorf("%s has schema version %d, newer than the %d this server knows", path, version, latest)
+	}
+	return nil
+}
+
+// Restore replaces the SQLite database at path by a backup, once a copy of the backup passes CheckIntegrity.
+// The server must not be running. The database it replaces is kept as path + ".before-restore".
+func Restore(backup, path string) error {
+	err := CheckIntegrity(backup)
+	if err != nil {
+		return err
+	}
+
+	// Check the copy as well, in case the backup changed or the copy went wrong
+	tmp := path + ".restore"
+	err = copyFile(backup, tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	err = CheckIntegrity(tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// Move the old database aside along with any journal, which would otherwise be applied to the restored one.
+	// Journals kept from an earlier restore don't belong to it.
+	for _, suffix := range []string{"-journal", "-wal", "-shm"} {
+		os.Remove(path + ".before-restore" + suffix)
+	}
+	for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+		err := os.Rename(path+suffix, path+".before-restore"+suffix)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(tmp, path)
+}
+
+// copyFile copies a file, making sure the copy is on disk before returning
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dest, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dest, src)
+	if err == nil {
+		err = dest.Sync()
+	}
+	closeErr := dest.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
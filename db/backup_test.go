@@ -0,0 +1,57 @@
+package db_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"seesharpsi/stixx_online/db"
+)
+
+func TestRestore(t *testing.T) {
+	// Characters that mean something in a SQLite URI must not confuse CheckIntegrity
+	dir := filepath.Join(t.TempDir(), "games #1 at 100%")
+	err := os.Mkdir(dir, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup := filepath.Join(dir, "backup.db")
+	path := filepath.Join(dir, "qwixx.db")
+
+	// The backup holds a game, the database to replace it doesn't
+	store := openStore(t, "sqlite", backup)
+	game := createGame(t, store)
+	store.Close()
+	openStore(t, "sqlite", path).Close()
+
+	err = db.CheckIntegrity(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	damaged := filepath.Join(dir, "damaged.db")
+	err = os.WriteFile(damaged, []byte("not a database"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.CheckIntegrity(damaged) == nil {
+		t.Error("a file that isn't a database passed the integrity check")
+	}
+	if db.Restore(damaged, path) == nil {
+		t.Error("restored a file that isn't a database")
+	}
+
+	err = db.Restore(backup, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := openStore(t, "sqlite", path)
+	_, err = restored.GetGame(game.GameCode)
+	if err != nil {
+		t.Errorf("the restored database lacks the backup's game: %s", err)
+	}
+	_, err = os.Stat(path + ".before-restore")
+	if err != nil {
+		t.Errorf("the replaced database wasn't kept: %s", err)
+	}
+}
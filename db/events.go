@@ -0,0 +1,74 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"seesharpsi/stixx_online/events"
+)
+
+// appendEvents adds events to the end of their games' logs, numbering them as they go.
+// The log is append-only: nothing ever updates its rows, which only go when their game is expired.
+// Archiving a game moves its log into the archive, so the game can still be replayed.
+func (s *SQLStore) appendEvents(tx *sql.Tx, evs []events.Event) error {
+	for i := range evs {
+		ev := &evs[i]
+
+		err := tx.QueryRow(s.q("SELECT COALESCE(MAX(seq), 0) + 1 FROM game_events WHERE game_id = ?"), ev.GameID).Scan(&ev.Seq)
+		if err != nil {
+			return err
+		}
+
+		var dice []byte
+		if len(ev.Dice) > 0 {
+			dice, err = json.Marshal(ev.Dice)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Exec(s.q(`
+			INSERT INTO game_events (game_id, seq, type, player_id, color, number, move, dice, name, reason)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`), ev.GameID, ev.Seq, ev.Type, ev.PlayerID, ev.Color, ev.Number, ev.Move, string(dice), ev.Name, ev.Reason)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLStore) GetGameEvents(gameID int) ([]events.Event, error) {
+	return s.gameEvents(s.db, gameID)
+}
+
+// gameEvents reads a game's log in order, inside or outside a transaction
+func (s *SQLStore) gameEvents(q querier, gameID int) ([]events.Event, error) {
+	rows, err := q.Query(s.q(`
+		SELECT seq, game_id, type, player_id, color, number, move, dice, name, reason
+		FROM game_events WHERE game_id = ? ORDER BY seq
+	`), gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var evs []events.Event
+	for rows.Next() {
+		var ev events.Event
+		var dice string
+		err := rows.Scan(&ev.Seq, &ev.GameID, &ev.Type, &ev.PlayerID, &ev.Color, &ev.Number, &ev.Move, &dice, &ev.Name, &ev.Reason)
+		if err != nil {
+			return nil, err
+		}
+		if dice != "" {
+			err = json.Unmarshal([]byte(dice), &ev.Dice)
+			if err != nil {
+				return nil, err
+			}
+		}
+		evs = append(evs, ev)
+	}
+
+	return evs, rows.Err()
+}
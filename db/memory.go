@@ -0,0 +1,606 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"seesharpsi/stixx_online/events"
+)
+
+// Memory keeps everything in memory, for tests and simulations. Nothing survives the process.
+// It keeps to the same rules as the SQL stores, down to their unique constraints.
+type Memory struct {
+	mu           sync.Mutex
+	ids          map[string]int // last ID handed out per table
+	games        map[int]*Game
+	players      map[int]*Player
+	clientSeeds  map[int]string // by player ID
+	marks        map[int][]PlayerMark
+	whiteActions map[int][]WhiteAction // by game ID
+	rolls        map[int][]Roll
+	events       map[int][]events.Event
+	activity     map[int]time.Time // when each game's latest event was added
+	sessions     map[string]Session
+	archive      map[string]*ArchivedGame // by game code
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		ids:          make(map[string]int),
+		games:        make(map[int]*Game),
+		players:      make(map[int]*Player),
+		clientSeeds:  make(map[int]string),
+		marks:        make(map[int][]PlayerMark),
+		whiteActions: make(map[int][]WhiteAction),
+		rolls:        make(map[int][]Roll),
+		events:       make(map[int][]events.Event),
+		activity:     make(map[int]time.Time),
+		sessions:     make(map[string]Session),
+		archive:      make(map[string]*ArchivedGame),
+	}
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+// nextID hands out the next ID of a table
+func (m *Memory) nextID(table string) int {
+	m.ids[table]++
+	return m.ids[table]
+}
+
+func (m *Memory) CreateGame() (*Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	game, err := newGame(func(gameCode string) (bool, error) {
+		return m.gameByCode(gameCode) != nil || m.archive[gameCode] != nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	game.ID = m.nextID("games")
+	game.CreatedAt = now()
+	stored := *game
+	m.games[game.ID] = &stored
+	return game, nil
+}
+
+func (m *Memory) gameByCode(gameCode string) *Game {
+	for _, game := range m.games {
+		if game.GameCode == gameCode {
+			return game
+		}
+	}
+	return nil
+}
+
+func (m *Memory) GetGame(gameCode string) (*Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	game := m.gameByCode(gameCode)
+	if game == nil {
+		return nil, ErrGameNotFound
+	}
+	found := *game
+	return &found, nil
+}
+
+func (m *Memory) GetGameByID(gameID int) (*Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	game, ok := m.games[gameID]
+	if !ok {
+		return nil, ErrGameNotFound
+	}
+	found := *game
+	return &found, nil
+}
+
+func (m *Memory) StartGame(gameID int) error {
+	started := []events.Event{{GameID: gameID, Type: events.GameStarted}}
+
+	m.mu.Lock()
+	game, ok := m.games[gameID]
+	if !ok || game.Status != "waiting" {
+		m.mu.Unlock()
+		return ErrAlreadyStarted
+	}
+	game.Status = "active"
+	game.Version++
+	m.appendEvents(started)
+	m.mu.Unlock()
+
+	events.Publish(started...)
+	return nil
+}
+
+func (m *Memory) SaveGame(change *GameChange) error {
+	m.mu.Lock()
+	game, ok := m.games[change.Game.ID]
+	if !ok || game.Version != change.Game.Version {
+		m.mu.Unlock()
+		return ErrConflict
+	}
+
+	// Check everything before writing anything, so a failed change leaves no trace
+	err := m.checkChange(change)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+
+	// The same columns as the SQL stores update
+	saved := change.Game
+	game.Status = saved.Status
+	game.CurrentPlayerIndex = saved.CurrentPlayerIndex
+	game.WhiteDice1, game.WhiteDice2 = saved.WhiteDice1, saved.WhiteDice2
+	game.RedDice, game.YellowDice, game.GreenDice, game.BlueDice = saved.RedDice, saved.YellowDice, saved.GreenDice, saved.BlueDice
+	game.RedLocked, game.YellowLocked, game.GreenLocked, game.BlueLocked = saved.RedLocked, saved.YellowLocked, saved.GreenLocked, saved.BlueLocked
+	game.PenaltiesTriggered = saved.PenaltiesTriggered
+	game.DiceRolled = saved.DiceRolled
+	game.RollNumber = saved.RollNumber
+	game.ColoredMarkUsed = saved.ColoredMarkUsed
+	game.TurnPhase = saved.TurnPhase
+	game.FinishReason = saved.FinishReason
+	game.Version++
+
+	for _, mark := range change.Marks {
+		mark.ID = m.nextID("player_marks")
+		mark.MarkedAt = now()
+		m.marks[mark.PlayerID] = append(m.marks[mark.PlayerID], mark)
+	}
+	for playerID, penalties := range change.Penalties {
+		if p, ok := m.players[playerID]; ok {
+			p.Penalties = penalties
+		}
+	}
+	for _, action := range change.WhiteActions {
+		action.ID = m.nextID("white_actions")
+		action.GameID = game.ID
+		action.ActedAt = now()
+		m.whiteActions[game.ID] = append(m.whiteActions[game.ID], action)
+	}
+	for _, roll := range change.Rolls {
+		roll.ID = m.nextID("rolls")
+		roll.GameID = game.ID
+		roll.RolledAt = now()
+		m.rolls[game.ID] = append(m.rolls[game.ID], roll)
+	}
+	m.appendEvents(change.Events)
+	m.mu.Unlock()
+
+	change.Game.Version++
+	events.Publish(change.Events...)
+	return nil
+}
+
+// checkChange enforces the unique constraints of the SQL schema on a change
+func (m *Memory) checkChange(change *GameChange) error {
+	gameID := change.Game.ID
+
+	marked := make(map[PlayerMark]bool)
+	for _, mark := range m.allMarks() {
+		marked[PlayerMark{PlayerID: mark.PlayerID, Color: mark.Color, Number: mark.Number}] = true
+	}
+	for _, mark := range change.Marks {
+		key := PlayerMark{PlayerID: mark.PlayerID, Color: mark.Color, Number: mark.Number}
+		if marked[key] {
+			return fmt.Errorf("player %d already marked %s %d", mark.PlayerID, mark.Color, mark.Number)
+		}
+		marked[key] = true
+	}
+
+	acted := make(map[[2]int]bool)
+	for _, action := range append(m.whiteActions[gameID], change.WhiteActions...) {
+		key := [2]int{action.PlayerID, action.RollNumber}
+		if acted[key] {
+			return fmt.Errorf("player %d already acted on the white sum of roll %d", action.PlayerID, action.RollNumber)
+		}
+		acted[key] = true
+	}
+
+	rolled := make(map[int]bool)
+	for _, roll := range append(m.rolls[gameID], change.Rolls...) {
+		if rolled[roll.RollNumber] {
+			return fmt.Errorf("roll %d of game %d was already recorded", roll.RollNumber, gameID)
+		}
+		rolled[roll.RollNumber] = true
+	}
+	return nil
+}
+
+func (m *Memory) allMarks() []PlayerMark {
+	var marks []PlayerMark
+	for _, playerMarks := range m.marks {
+		marks = append(marks, playerMarks...)
+	}
+	return marks
+}
+
+// appendEvents adds events to the end of their games' logs, numbering them as they go
+func (m *Memory) appendEvents(evs []events.Event) {
+	for i := range evs {
+		ev := &evs[i]
+		ev.Seq = len(m.events[ev.GameID]) + 1
+
+		stored := *ev
+		stored.Dice = append([]int(nil), ev.Dice...)
+		m.events[ev.GameID] = append(m.events[ev.GameID], stored)
+		m.activity[ev.GameID] = now()
+	}
+}
+
+func (m *Memory) GetBotGames() ([]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var gameIDs []int
+	for id, game := range m.games {
+		if game.Status != "active" {
+			continue
+		}
+		for _, p := range m.players {
+			if p.GameID == id && p.BotStrategy != "" {
+				gameIDs = append(gameIDs, id)
+				break
+			}
+		}
+	}
+	sort.Ints(gameIDs)
+	return gameIDs, nil
+}
+
+func (m *Memory) GetIdleGames(status string, idleSince time.Time) ([]Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var games []Game
+	for _, game := range m.games {
+		if game.Status == status && m.lastActivity(game).Before(idleSince) {
+			games = append(games, *game)
+		}
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].ID < games[j].ID })
+	return games, nil
+}
+
+// lastActivity returns when a game's latest event was added, or when it was created if it has none
+func (m *Memory) lastActivity(game *Game) time.Time {
+	if last, ok := m.activity[game.ID]; ok {
+		return last
+	}
+	return game.CreatedAt
+}
+
+func (m *Memory) ExpireGame(gameID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	game, ok := m.games[gameID]
+	if !ok {
+		return ErrGameNotFound
+	}
+	if game.Status != "waiting" {
+		return ErrAlreadyStarted
+	}
+	m.deleteGame(gameID)
+	return nil
+}
+
+func (m *Memory) ArchiveGame(gameID int, players []ArchivedPlayer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	game, ok := m.games[gameID]
+	if !ok {
+		return ErrGameNotFound
+	}
+	if game.Status != "finished" {
+		return ErrNotFinished
+	}
+
+	archived := &ArchivedGame{
+		ID:           m.nextID("archived_games"),
+		GameCode:     game.GameCode,
+		CreatedAt:    game.CreatedAt,
+		FinishedAt:   m.lastActivity(game),
+		FinishReason: game.FinishReason,
+		Rolls:        game.RollNumber,
+		ArchivedAt:   now(),
+		Events:       append([]events.Event(nil), m.events[gameID]...),
+	}
+	for _, p := range players {
+		p.Marks = copyMarks(p.Marks)
+		archived.Players = append(archived.Players, p)
+	}
+	sort.Slice(archived.Players, func(i, j int) bool { return archived.Players[i].TurnOrder < archived.Players[j].TurnOrder })
+
+	m.archive[game.GameCode] = archived
+	m.deleteGame(gameID)
+	return nil
+}
+
+// deleteGame removes a game with everything belonging to it
+func (m *Memory) deleteGame(gameID int) {
+	for _, p := range m.gamePlayers(gameID) {
+		for tokenHash, session := range m.sessions {
+			if session.PlayerID == p.ID {
+				delete(m.sessions, tokenHash)
+			}
+		}
+		delete(m.marks, p.ID)
+		delete(m.clientSeeds, p.ID)
+		delete(m.players, p.ID)
+	}
+	delete(m.whiteActions, gameID)
+	delete(m.rolls, gameID)
+	delete(m.events, gameID)
+	delete(m.activity, gameID)
+	delete(m.games, gameID)
+}
+
+func (m *Memory) GetArchivedGame(gameCode string) (*ArchivedGame, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	archived, ok := m.archive[gameCode]
+	if !ok {
+		return nil, ErrGameNotFound
+	}
+
+	found := *archived
+	found.Events = append([]events.Event(nil), archived.Events...)
+	found.Players = nil
+	for _, p := range archived.Players {
+		p.Marks = copyMarks(p.Marks)
+		found.Players = append(found.Players, p)
+	}
+	return &found, nil
+}
+
+func copyMarks(marks map[string][]int) map[string][]int {
+	copied := make(map[string][]int)
+	for color, numbers := range marks {
+		copied[color] = append([]int(nil), numbers...)
+	}
+	return copied
+}
+
+func (m *Memory) JoinGame(gameCode, playerName, clientSeed string, maxPlayers int) (*Player, error) {
+	m.mu.Lock()
+	game := m.gameByCode(gameCode)
+	if game == nil {
+		m.mu.Unlock()
+		return nil, ErrGameNotFound
+	}
+
+	// Player found, return them (rejoin) unless the seat is played by the server
+	players := m.gamePlayers(game.ID)
+	for _, p := range players {
+		if p.Name == playerName {
+			m.mu.Unlock()
+			if p.BotStrategy != "" {
+				return nil, ErrBotSeat
+			}
+			return &p, nil
+		}
+	}
+
+	if game.Status != "waiting" {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w, so you can't join with a new name", ErrAlreadyStarted)
+	}
+	if len(players) >= maxPlayers {
+		m.mu.Unlock()
+		return nil, ErrGameFull
+	}
+
+	clientSeed, err := newClientSeed(game, len(players), clientSeed)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	player := m.addPlayer(game.ID, playerName, "", clientSeed)
+	joined := []events.Event{{GameID: game.ID, Type: events.PlayerJoined, PlayerID: player.ID, Name: playerName}}
+	m.appendEvents(joined)
+	m.mu.Unlock()
+
+	events.Publish(joined...)
+	return player, nil
+}
+
+func (m *Memory) AddBot(gameCode, strategy string, maxPlayers int) (*Player, error) {
+	m.mu.Lock()
+	game := m.gameByCode(gameCode)
+	if game == nil {
+		m.mu.Unlock()
+		return nil, ErrGameNotFound
+	}
+	if game.Status != "waiting" {
+		m.mu.Unlock()
+		return nil, ErrAlreadyStarted
+	}
+
+	var names []string
+	for _, p := range m.gamePlayers(game.ID) {
+		names = append(names, p.Name)
+	}
+	playerCount := len(names)
+	if playerCount >= maxPlayers {
+		m.mu.Unlock()
+		return nil, ErrGameFull
+	}
+
+	clientSeed, err := newClientSeed(game, playerCount, "")
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	player := m.addPlayer(game.ID, botName(playerCount+1, strategy, names), strategy, clientSeed)
+	joined := []events.Event{{GameID: game.ID, Type: events.PlayerJoined, PlayerID: player.ID, Name: player.Name}}
+	m.appendEvents(joined)
+	m.mu.Unlock()
+
+	events.Publish(joined...)
+	return player, nil
+}
+
+// addPlayer seats a player after the ones already in a game
+func (m *Memory) addPlayer(gameID int, name, strategy, clientSeed string) *Player {
+	player := &Player{
+		ID:          m.nextID("players"),
+		GameID:      gameID,
+		Name:        name,
+		TurnOrder:   len(m.gamePlayers(gameID)),
+		JoinedAt:    now(),
+		IsActive:    true,
+		BotStrategy: strategy,
+	}
+
+	stored := *player
+	m.players[player.ID] = &stored
+	m.clientSeeds[player.ID] = clientSeed
+	return player
+}
+
+// gamePlayers returns copies of a game's players in turn order
+func (m *Memory) gamePlayers(gameID int) []Player {
+	var players []Player
+	for _, p := range m.players {
+		if p.GameID == gameID {
+			players = append(players, *p)
+		}
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].TurnOrder < players[j].TurnOrder })
+	return players
+}
+
+func (m *Memory) GetPlayers(gameID int) ([]Player, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.gamePlayers(gameID), nil
+}
+
+func (m *Memory) GetClientSeeds(gameID int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var seeds []string
+	for _, p := range m.gamePlayers(gameID) {
+		seeds = append(seeds, m.clientSeeds[p.ID])
+	}
+	return seeds, nil
+}
+
+func (m *Memory) GetPlayerMarks(playerID int) ([]PlayerMark, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	marks := append([]PlayerMark(nil), m.marks[playerID]...)
+	sort.Slice(marks, func(i, j int) bool {
+		if marks[i].Color != marks[j].Color {
+			return marks[i].Color < marks[j].Color
+		}
+		return marks[i].Number < marks[j].Number
+	})
+	return marks, nil
+}
+
+func (m *Memory) GetWhiteActions(gameID, rollNumber int) (map[int]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	actions := make(map[int]string)
+	for _, action := range m.whiteActions[gameID] {
+		if action.RollNumber == rollNumber {
+			actions[action.PlayerID] = action.Action
+		}
+	}
+	return actions, nil
+}
+
+func (m *Memory) GetRolls(gameID int) ([]Roll, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rolls := append([]Roll(nil), m.rolls[gameID]...)
+	sort.Slice(rolls, func(i, j int) bool { return rolls[i].RollNumber < rolls[j].RollNumber })
+	return rolls, nil
+}
+
+func (m *Memory) GetGameEvents(gameID int) ([]events.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var evs []events.Event
+	for _, ev := range m.events[gameID] {
+		ev.Dice = append([]int(nil), ev.Dice...)
+		evs = append(evs, ev)
+	}
+	return evs, nil
+}
+
+func (m *Memory) CreateSession(session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Clean up expired sessions while we're writing anyway
+	for tokenHash, s := range m.sessions {
+		if !s.ExpiresAt.After(now()) {
+			delete(m.sessions, tokenHash)
+		}
+	}
+
+	if _, ok := m.sessions[session.TokenHash]; ok {
+		return fmt.Errorf("session already exists")
+	}
+	m.sessions[session.TokenHash] = *session
+	return nil
+}
+
+func (m *Memory) GetSession(tokenHash string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[tokenHash]
+	if !ok || !session.ExpiresAt.After(now()) {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (m *Memory) RotateSession(oldTokenHash string, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old, ok := m.sessions[oldTokenHash]
+	if !ok {
+		return ErrSessionNotFound
+	}
+	if _, taken := m.sessions[session.TokenHash]; taken && session.TokenHash != oldTokenHash {
+		return fmt.Errorf("session already exists")
+	}
+
+	delete(m.sessions, oldTokenHash)
+	old.TokenHash = session.TokenHash
+	old.CreatedAt = session.CreatedAt
+	old.ExpiresAt = session.ExpiresAt
+	m.sessions[old.TokenHash] = old
+	return nil
+}
+
+func (m *Memory) DeleteSession(tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, tokenHash)
+	return nil
+}
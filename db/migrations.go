@@ -0,0 +1,367 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Migration is one versioned change to the database schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *sql.Tx) error
+}
+
+// sqliteMigrations lists every change to the SQLite schema in the order they are applied.
+// Never edit or reorder a migration once it has shipped; add a new one instead.
+//
+// SQLite databases created before migrations were tracked are at an unknown version, so each step
+// only creates tables and adds columns that don't exist yet and can safely run again.
+var sqliteMigrations = []Migration{
+	{1, "create games, players and marks", execSQL(`
+		CREATE TABLE IF NOT EXISTS games (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			game_code TEXT UNIQUE NOT NULL,
+			status TEXT DEFAULT 'waiting', -- waiting, active, finished
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			current_player_index INTEGER DEFAULT 0,
+			white_dice_1 INTEGER DEFAULT 0,
+			white_dice_2 INTEGER DEFAULT 0,
+			red_dice INTEGER DEFAULT 0, -- 0 when the die is out of play
+			yellow_dice INTEGER DEFAULT 0,
+			green_dice INTEGER DEFAULT 0,
+			blue_dice INTEGER DEFAULT 0,
+			red_locked BOOLEAN DEFAULT FALSE,
+			yellow_locked BOOLEAN DEFAULT FALSE,
+			green_locked BOOLEAN DEFAULT FALSE,
+			blue_locked BOOLEAN DEFAULT FALSE,
+			penalties_triggered INTEGER DEFAULT 0,
+			dice_rolled BOOLEAN DEFAULT FALSE,
+			colored_mark_used BOOLEAN DEFAULT FALSE
+		);
+
+		CREATE TABLE IF NOT EXISTS players (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			game_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			turn_order INTEGER DEFAULT 0,
+			joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			penalties INTEGER DEFAULT 0,
+			is_active BOOLEAN DEFAULT TRUE,
+			FOREIGN KEY (game_id) REFERENCES games(id) ON DELETE CASCADE,
+			UNIQUE(game_id, name)
+		);
+
+		CREATE TABLE IF NOT EXISTS player_marks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			player_id INTEGER NOT NULL,
+			color TEXT NOT NULL, -- red, yellow, green, blue
+			number INTEGER NOT NULL,
+			marked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+			UNIQUE(player_id, color, number)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_games_code ON games(game_code);
+		CREATE INDEX IF NOT EXISTS idx_players_game ON players(game_id);
+		CREATE INDEX IF NOT EXISTS idx_marks_player ON player_marks(player_id);
+	`)},
+
+	{2, "track white dice use per player and roll", steps(
+		addColumns("games", "roll_number INTEGER DEFAULT 0"),
+		dropColumn("games", "white_mark_used"),
+		execSQL(`
+			CREATE TABLE IF NOT EXISTS white_actions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				game_id INTEGER NOT NULL,
+				player_id INTEGER NOT NULL,
+				roll_number INTEGER NOT NULL,
+				action TEXT NOT NULL, -- marked, passed
+				acted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (game_id) REFERENCES games(id) ON DELETE CASCADE,
+				FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+				UNIQUE(game_id, player_id, roll_number)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_white_actions_roll ON white_actions(game_id, roll_number);
+		`),
+	)},
+
+	{3, "record every roll", execSQL(`
+		CREATE TABLE IF NOT EXISTS rolls (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			game_id INTEGER NOT NULL,
+			roll_number INTEGER NOT NULL,
+			player_id INTEGER NOT NULL,
+			white_dice_1 INTEGER NOT NULL,
+			white_dice_2 INTEGER NOT NULL,
+			red_dice INTEGER NOT NULL, -- 0 when the die is out of play
+			yellow_dice INTEGER NOT NULL,
+			green_dice INTEGER NOT NULL,
+			blue_dice INTEGER NOT NULL,
+			rolled_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (game_id) REFERENCES games(id) ON DELETE CASCADE,
+			FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+			UNIQUE(game_id, roll_number)
+		);
+	`)},
+
+	{4, "store turn phases", addColumns("games",
+		"turn_phase TEXT DEFAULT ''", // white, colored, done; empty before the roll
+	)},
+
+	{5, "store why a game finished", addColumns("games",
+		"finish_reason TEXT DEFAULT ''", // two_locks, four_penalties
+	)},
+
+	{6, "version games for optimistic concurrency", addColumns("games",
+		"version INTEGER DEFAULT 0", // bumped on every update
+	)},
+
+	{7, "store sessions", execSQL(`
+		CREATE TABLE IF NOT EXISTS sessions (
+			token_hash TEXT PRIMARY KEY, -- SHA-256 of the session cookie value
+			player_id INTEGER NOT NULL,
+			game_code TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_sessions_expires ON sessions(expires_at);
+	`)},
+
+	{8, "add computer players", addColumns("players",
+		"bot_strategy TEXT DEFAULT ''", // strategy of computer players, empty for people
+	)},
+
+	{9, "record the seed of each roll", addColumns("rolls",
+		"seed INTEGER NOT NULL DEFAULT 0", // dice.Faces(seed) gives the faces of seeded dice; fair dice record dice.FairSeed and derive faces from the HMAC; 0 for scripted dice
+	)},
+
+	{10, "add provably fair dice", steps(
+		addColumns("games",
+			"server_seed TEXT DEFAULT ''", // secret until the game finishes
+			"seed_hash TEXT DEFAULT ''",   // commitment to the server seed, public from the start
+		),
+		addColumns("players",
+			"client_seed TEXT DEFAULT ''", // mixed into every roll of the game, see package dice
+		),
+	)},
+
+	{11, "log game events", execSQL(`
+		CREATE TABLE IF NOT EXISTS game_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			game_id INTEGER NOT NULL,
+			seq INTEGER NOT NULL, -- position in the game's log, starting at 1
+			type TEXT NOT NULL, -- see package events
+			player_id INTEGER DEFAULT 0,
+			color TEXT DEFAULT '',
+			number INTEGER DEFAULT 0,
+			move TEXT DEFAULT '', -- white or colored, for marks
+			dice TEXT DEFAULT '', -- JSON array of the six dice, for rolls
+			name TEXT DEFAULT '', -- for players joining
+			reason TEXT DEFAULT '', -- for the game finishing
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (game_id) REFERENCES games(id) ON DELETE CASCADE,
+			UNIQUE(game_id, seq)
+		);
+	`)},
+
+	{12, "archive finished games", execSQL(`
+		CREATE TABLE IF NOT EXISTS archived_games (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			game_code TEXT UNIQUE NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			finished_at TIMESTAMP NOT NULL, -- when the game's last event happened
+			finish_reason TEXT DEFAULT '',
+			rolls INTEGER DEFAULT 0,
+			archived_at TIMESTAMP NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS archived_players (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			archived_game_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			turn_order INTEGER DEFAULT 0,
+			bot_strategy TEXT DEFAULT '',
+			penalties INTEGER DEFAULT 0,
+			score INTEGER DEFAULT 0,
+			marks TEXT DEFAULT '', -- JSON object of the numbers marked in each row
+			FOREIGN KEY (archived_game_id) REFERENCES archived_games(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_archived_players_game ON archived_players(archived_game_id);
+		CREATE INDEX IF NOT EXISTS idx_games_status ON games(status);
+	`)},
+
+	{13, "keep the event log of archived games", addColumns("archived_games",
+		"events TEXT DEFAULT ''", // JSON array of the game's events, empty for games archived before
+	)},
+}
+
+// Migrations returns every migration of the store's schema in order
+func (s *SQLStore) Migrations() []Migration {
+	return s.migrations
+}
+
+// SchemaVersion returns the version of the last migration applied to the database, 0 for none
+func (s *SQLStore) SchemaVersion() (int, error) {
+	err := s.createMigrationsTable()
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = s.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
+
+// PendingMigrations returns the migrations that haven't been applied to the database yet, in order
+func (s *SQLStore) PendingMigrations() ([]Migration, error) {
+	version, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range s.migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate applies every pending migration in order, each in its own transaction,
+// and returns the ones it applied. It stops at the first migration that fails.
+func (s *SQLStore) Migrate() ([]Migration, error) {
+	err := s.createMigrationsTable()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range s.migrations {
+		ran := false
+		err := s.withTx(func(tx *sql.Tx) error {
+			// Checked inside the transaction, which holds the write lock, so two servers
+			// starting at once don't both apply a migration
+			if s.postgres {
+				_, err := tx.Exec("LOCK TABLE schema_migrations IN EXCLUSIVE MODE")
+				if err != nil {
+					return err
+				}
+			}
+
+			var version int
+			err := tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+			if err != nil || m.Version <= version {
+				return err
+			}
+
+			err = m.Up(tx)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(s.q("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"), m.Version, m.Name, time.Now())
+			ran = err == nil
+			return err
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		if ran {
+			applied = append(applied, m)
+		}
+	}
+	return applied, nil
+}
+
+func (s *SQLStore) createMigrationsTable() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`)
+	return err
+}
+
+// execSQL runs a script of statements
+func execSQL(script string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(script)
+		return err
+	}
+}
+
+// steps runs several migration steps one after the other
+func steps(fns ...func(tx *sql.Tx) error) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, fn := range fns {
+			err := fn(tx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addColumns adds columns to a table, given as they would appear in CREATE TABLE,
+// skipping the ones the table already has
+func addColumns(table string, columns ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		existing, err := tableColumns(tx, table)
+		if err != nil {
+			return err
+		}
+
+		for _, column := range columns {
+			name := strings.Fields(column)[0]
+			if existing[name] {
+				continue
+			}
+			_, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, column))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// dropColumn removes a column from a table if it has it
+func dropColumn(table, column string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		existing, err := tableColumns(tx, table)
+		if err != nil || !existing[column] {
+			return err
+		}
+		_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column))
+		return err
+	}
+}
+
+func tableColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		err := rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
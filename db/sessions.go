@@ -0,0 +1,79 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Session links a browser to a player in a game.
+// Only the hash of the session token is stored, so a leaked database can't be used to log in.
+type Session struct {
+	TokenHash string
+	PlayerID  int
+	GameCode  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (s *SQLStore) CreateSession(session *Session) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		// Clean up expired sessions while we're writing anyway
+		_, err := tx.Exec(s.q("DELETE FROM sessions WHERE expires_at <= ?"), now())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(s.q(`
+			INSERT INTO sessions (token_hash, player_id, game_code, created_at, expires_at)
+			VALUES (?, ?, ?, ?, ?)
+		`), session.TokenHash, session.PlayerID, session.GameCode, session.CreatedAt, session.ExpiresAt)
+		return err
+	})
+}
+
+func (s *SQLStore) GetSession(tokenHash string) (*Session, error) {
+	session := &Session{}
+	err := s.db.QueryRow(s.q(`
+		SELECT token_hash, player_id, game_code, created_at, expires_at
+		FROM sessions WHERE token_hash = ? AND expires_at > ?
+	`), tokenHash, now()).Scan(
+		&session.TokenHash, &session.PlayerID, &session.GameCode, &session.CreatedAt, &session.ExpiresAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *SQLStore) RotateSession(oldTokenHash string, session *Session) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(s.q(`
+			UPDATE sessions SET token_hash = ?, created_at = ?, expires_at = ?
+			WHERE token_hash = ?
+		`), session.TokenHash, session.CreatedAt, session.ExpiresAt, oldTokenHash)
+		if err != nil {
+			return err
+		}
+
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrSessionNotFound
+		}
+		return nil
+	})
+}
+
+func (s *SQLStore) DeleteSession(tokenHash string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(s.q("DELETE FROM sessions WHERE token_hash = ?"), tokenHash)
+		return err
+	})
+}
@@ -0,0 +1,556 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"seesharpsi/stixx_online/events"
+)
+
+// SQLStore keeps everything in a SQL database, SQLite or PostgreSQL.
+// Queries are written for SQLite and rewritten for PostgreSQL where the two differ.
+type SQLStore struct {
+	db         *sql.DB
+	postgres   bool
+	migrations []Migration
+}
+
+// NewSQLite opens the SQLite database at path without changing its schema.
+// The program has to import a driver registered as "sqlite3", such as github.com/mattn/go-sqlite3,
+// which keeps cgo out of programs that never open a database.
+func NewSQLite(path string) (*SQLStore, error) {
+	// Wait for other writers instead of failing, and take the write lock when a transaction begins
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		return nil, err
+	}
+	return &SQLStore{db: db, migrations: sqliteMigrations}, nil
+}
+
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// DB returns the underlying database, for work the Store interface doesn't cover such as backups
+func (s *SQLStore) DB() *sql.DB {
+	return s.db
+}
+
+// Postgres reports whether the store is a PostgreSQL database rather than SQLite
+func (s *SQLStore) Postgres() bool {
+	return s.postgres
+}
+
+// q rewrites the ? placeholders of a query for the database in use
+func (s *SQLStore) q(query string) string {
+	if !s.postgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// forUpdate is appended to a query reading a game that the transaction goes on to change.
+// SQLite transactions already hold the write lock from the start.
+func (s *SQLStore) forUpdate() string {
+	if s.postgres {
+		return " FOR UPDATE"
+	}
+	return ""
+}
+
+// withTx runs fn inside a transaction, committing if it succeeds and rolling back if it returns an error
+func (s *SQLStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLStore) CreateGame() (*Game, error) {
+	var game *Game
+	err := s.withTx(func(tx *sql.Tx) error {
+		var err error
+		// Codes of archived games aren't handed out again, so old links never lead to a new game
+		game, err = newGame(func(gameCode string) (bool, error) {
+			var exists bool
+			err := tx.QueryRow(s.q(`
+				SELECT EXISTS(SELECT 1 FROM games WHERE game_code = ?)
+				    OR EXISTS(SELECT 1 FROM archived_games WHERE game_code = ?)
+			`), gameCode, gameCode).Scan(&exists)
+			return exists, err
+		})
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRow(
+			s.q("INSERT INTO games (game_code, server_seed, seed_hash) VALUES (?, ?, ?) RETURNING id, created_at"),
+			game.GameCode, game.ServerSeed, game.SeedHash,
+		).Scan(&game.ID, &game.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
+const gameColumns = `id, game_code, status, created_at, current_player_index,
+	white_dice_1, white_dice_2, red_dice, yellow_dice, green_dice, blue_dice,
+	red_locked, yellow_locked, green_locked, blue_locked, penalties_triggered,
+	dice_rolled, roll_number, colored_mark_used, turn_phase, finish_reason, version,
+	server_seed, seed_hash`
+
+// scanner is a *sql.Row or *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is a *sql.DB or *sql.Tx
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func scanGame(row scanner) (*Game, error) {
+	game := &Game{}
+	err := row.Scan(
+		&game.ID, &game.GameCode, &game.Status, &game.CreatedAt, &game.CurrentPlayerIndex,
+		&game.WhiteDice1, &game.WhiteDice2, &game.RedDice, &game.YellowDice, &game.GreenDice, &game.BlueDice,
+		&game.RedLocked, &game.YellowLocked, &game.GreenLocked, &game.BlueLocked, &game.PenaltiesTriggered,
+		&game.DiceRolled, &game.RollNumber, &game.ColoredMarkUsed, &game.TurnPhase, &game.FinishReason, &game.Version,
+		&game.ServerSeed, &game.SeedHash,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrGameNotFound
+	}
+
+	return game, err
+}
+
+func (s *SQLStore) GetGame(gameCode string) (*Game, error) {
+	return scanGame(s.db.QueryRow(s.q("SELECT "+gameColumns+" FROM games WHERE game_code = ?"), gameCode))
+}
+
+func (s *SQLStore) GetGameByID(gameID int) (*Game, error) {
+	return scanGame(s.db.QueryRow(s.q("SELECT "+gameColumns+" FROM games WHERE id = ?"), gameID))
+}
+
+func (s *SQLStore) StartGame(gameID int) error {
+	started := []events.Event{{GameID: gameID, Type: events.GameStarted}}
+	err := s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			s.q("UPDATE games SET status = 'active', version = version + 1 WHERE id = ? AND status = 'waiting'"),
+			gameID,
+		)
+		if err != nil {
+			return err
+		}
+
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrAlreadyStarted
+		}
+		return s.appendEvents(tx, started)
+	})
+	if err != nil {
+		return err
+	}
+
+	events.Publish(started...)
+	return nil
+}
+
+func (s *SQLStore) SaveGame(change *GameChange) error {
+	game := change.Game
+	err := s.withTx(func(tx *sql.Tx) error {
+		// Claim the game first so a concurrent change is caught before anything else is written
+		result, err := tx.Exec(s.q(`
+			UPDATE games
+			SET status = ?, current_player_index = ?,
+			    white_dice_1 = ?, white_dice_2 = ?, red_dice = ?,
+			    yellow_dice = ?, green_dice = ?, blue_dice = ?,
+			    red_locked = ?, yellow_locked = ?, green_locked = ?, blue_locked = ?,
+			    penalties_triggered = ?, dice_rolled = ?, roll_number = ?, colored_mark_used = ?,
+			    turn_phase = ?, finish_reason = ?, version = version + 1
+			WHERE id = ? AND version = ?
+		`), game.Status, game.CurrentPlayerIndex,
+			game.WhiteDice1, game.WhiteDice2, game.RedDice,
+			game.YellowDice, game.GreenDice, game.BlueDice,
+			game.RedLocked, game.YellowLocked, game.GreenLocked, game.BlueLocked,
+			game.PenaltiesTriggered, game.DiceRolled, game.RollNumber, game.ColoredMarkUsed,
+			game.TurnPhase, game.FinishReason, game.ID, game.Version)
+		if err != nil {
+			return err
+		}
+
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrConflict
+		}
+
+		for _, mark := range change.Marks {
+			_, err := tx.Exec(
+				s.q("INSERT INTO player_marks (player_id, color, number) VALUES (?, ?, ?)"),
+				mark.PlayerID, mark.Color, mark.Number,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		for playerID, penalties := range change.Penalties {
+			_, err := tx.Exec(s.q("UPDATE players SET penalties = ? WHERE id = ?"), penalties, playerID)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Each player may act on the white sum only once per roll
+		for _, action := range change.WhiteActions {
+			_, err := tx.Exec(
+				s.q("INSERT INTO white_actions (game_id, player_id, roll_number, action) VALUES (?, ?, ?, ?)"),
+				game.ID, action.PlayerID, action.RollNumber, action.Action,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Colored dice that are out of play are stored as 0
+		for _, roll := range change.Rolls {
+			_, err := tx.Exec(s.q(`
+				INSERT INTO rolls (game_id, roll_number, player_id,
+				                   white_dice_1, white_dice_2, red_dice, yellow_dice, green_dice, blue_dice, seed)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`), game.ID, roll.RollNumber, roll.PlayerID,
+				roll.WhiteDice1, roll.WhiteDice2, roll.RedDice, roll.YellowDice, roll.GreenDice, roll.BlueDice, roll.Seed)
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.appendEvents(tx, change.Events)
+	})
+	if err != nil {
+		return err
+	}
+
+	game.Version++
+	events.Publish(change.Events...)
+	return nil
+}
+
+func (s *SQLStore) GetBotGames() ([]int, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT g.id FROM games g
+		JOIN players p ON p.game_id = g.id
+		WHERE g.status = 'active' AND p.bot_strategy != ''
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gameIDs []int
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		gameIDs = append(gameIDs, id)
+	}
+
+	return gameIDs, rows.Err()
+}
+
+const playerColumns = "id, game_id, name, turn_order, joined_at, penalties, is_active, bot_strategy"
+
+func scanPlayer(scan func(dest ...any) error) (Player, error) {
+	var p Player
+	err := scan(&p.ID, &p.GameID, &p.Name, &p.TurnOrder, &p.JoinedAt, &p.Penalties, &p.IsActive, &p.BotStrategy)
+	return p, err
+}
+
+func (s *SQLStore) JoinGame(gameCode, playerName, clientSeed string, maxPlayers int) (*Player, error) {
+	var player *Player
+	var joined []events.Event // nothing when rejoining
+
+	err := s.withTx(func(tx *sql.Tx) error {
+		// Get game
+		game, err := scanGame(tx.QueryRow(s.q("SELECT "+gameColumns+" FROM games WHERE game_code = ?"+s.forUpdate()), gameCode))
+		if err != nil {
+			return err
+		}
+
+		// Check for existing player with that name
+		existingPlayer, err := scanPlayer(tx.QueryRow(
+			s.q("SELECT "+playerColumns+" FROM players WHERE game_id = ? AND name = ?"), game.ID, playerName,
+		).Scan)
+
+		if err == nil {
+			// Player found, return them (rejoin) unless the seat is played by the server
+			if existingPlayer.BotStrategy != "" {
+				return ErrBotSeat
+			}
+			player = &existingPlayer
+			return nil
+		}
+
+		if err != sql.ErrNoRows {
+			// A different database error occurred
+			return err
+		}
+
+		// Player does not exist, check if game is open for new players
+		if game.Status != "waiting" {
+			return fmt.Errorf("%w, so you can't join with a new name", ErrAlreadyStarted)
+		}
+
+		// Count existing players to determine turn order for new player
+		var playerCount int
+		err = tx.QueryRow(s.q("SELECT COUNT(*) FROM players WHERE game_id = ?"), game.ID).Scan(&playerCount)
+		if err != nil {
+			return err
+		}
+		if playerCount >= maxPlayers {
+			return ErrGameFull
+		}
+
+		clientSeed, err = newClientSeed(game, playerCount, clientSeed)
+		if err != nil {
+			return err
+		}
+
+		// Create new player
+		player = &Player{GameID: game.ID, Name: playerName, TurnOrder: playerCount, IsActive: true}
+		err = tx.QueryRow(
+			s.q("INSERT INTO players (game_id, name, turn_order, client_seed) VALUES (?, ?, ?, ?) RETURNING id, joined_at"),
+			game.ID, playerName, playerCount, clientSeed,
+		).Scan(&player.ID, &player.JoinedAt)
+		if err != nil {
+			return err
+		}
+
+		joined = []events.Event{{GameID: game.ID, Type: events.PlayerJoined, PlayerID: player.ID, Name: playerName}}
+		return s.appendEvents(tx, joined)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events.Publish(joined...)
+	return player, nil
+}
+
+func (s *SQLStore) AddBot(gameCode, strategy string, maxPlayers int) (*Player, error) {
+	var player *Player
+	var joined []events.Event
+
+	err := s.withTx(func(tx *sql.Tx) error {
+		game, err := scanGame(tx.QueryRow(s.q("SELECT "+gameColumns+" FROM games WHERE game_code = ?"+s.forUpdate()), gameCode))
+		if err != nil {
+			return err
+		}
+		if game.Status != "waiting" {
+			return ErrAlreadyStarted
+		}
+
+		names, err := s.playerNames(tx, game.ID)
+		if err != nil {
+			return err
+		}
+		playerCount := len(names)
+		if playerCount >= maxPlayers {
+			return ErrGameFull
+		}
+
+		clientSeed, err := newClientSeed(game, playerCount, "")
+		if err != nil {
+			return err
+		}
+
+		player = &Player{
+			GameID:      game.ID,
+			Name:        botName(playerCount+1, strategy, names),
+			TurnOrder:   playerCount,
+			IsActive:    true,
+			BotStrategy: strategy,
+		}
+		err = tx.QueryRow(
+			s.q("INSERT INTO players (game_id, name, turn_order, bot_strategy, client_seed) VALUES (?, ?, ?, ?, ?) RETURNING id, joined_at"),
+			game.ID, player.Name, playerCount, strategy, clientSeed,
+		).Scan(&player.ID, &player.JoinedAt)
+		if err != nil {
+			return err
+		}
+
+		joined = []events.Event{{GameID: game.ID, Type: events.PlayerJoined, PlayerID: player.ID, Name: player.Name}}
+		return s.appendEvents(tx, joined)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events.Publish(joined...)
+	return player, nil
+}
+
+// playerNames returns the names of a game's players
+func (s *SQLStore) playerNames(tx *sql.Tx, gameID int) ([]string, error) {
+	rows, err := tx.Query(s.q("SELECT name FROM players WHERE game_id = ?"), gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		err := rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+func (s *SQLStore) GetPlayers(gameID int) ([]Player, error) {
+	rows, err := s.db.Query(s.q("SELECT "+playerColumns+" FROM players WHERE game_id = ? ORDER BY turn_order"), gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var players []Player
+	for rows.Next() {
+		p, err := scanPlayer(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+
+	return players, rows.Err()
+}
+
+func (s *SQLStore) GetClientSeeds(gameID int) ([]string, error) {
+	rows, err := s.db.Query(s.q("SELECT client_seed FROM players WHERE game_id = ? ORDER BY turn_order"), gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seeds []string
+	for rows.Next() {
+		var seed string
+		err := rows.Scan(&seed)
+		if err != nil {
+			return nil, err
+		}
+		seeds = append(seeds, seed)
+	}
+
+	return seeds, rows.Err()
+}
+
+func (s *SQLStore) GetPlayerMarks(playerID int) ([]PlayerMark, error) {
+	rows, err := s.db.Query(s.q(`
+		SELECT id, player_id, color, number, marked_at
+		FROM player_marks WHERE player_id = ? ORDER BY color, number
+	`), playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var marks []PlayerMark
+	for rows.Next() {
+		var m PlayerMark
+		err := rows.Scan(&m.ID, &m.PlayerID, &m.Color, &m.Number, &m.MarkedAt)
+		if err != nil {
+			return nil, err
+		}
+		marks = append(marks, m)
+	}
+
+	return marks, rows.Err()
+}
+
+func (s *SQLStore) GetRolls(gameID int) ([]Roll, error) {
+	rows, err := s.db.Query(s.q(`
+		SELECT id, game_id, roll_number, player_id,
+		       white_dice_1, white_dice_2, red_dice, yellow_dice, green_dice, blue_dice, seed, rolled_at
+		FROM rolls WHERE game_id = ? ORDER BY roll_number
+	`), gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rolls []Roll
+	for rows.Next() {
+		var r Roll
+		err := rows.Scan(&r.ID, &r.GameID, &r.RollNumber, &r.PlayerID,
+			&r.WhiteDice1, &r.WhiteDice2, &r.RedDice, &r.YellowDice, &r.GreenDice, &r.BlueDice, &r.Seed, &r.RolledAt)
+		if err != nil {
+			return nil, err
+		}
+		rolls = append(rolls, r)
+	}
+
+	return rolls, rows.Err()
+}
+
+func (s *SQLStore) GetWhiteActions(gameID, rollNumber int) (map[int]string, error) {
+	rows, err := s.db.Query(s.q(`
+		SELECT player_id, action
+		FROM white_actions WHERE game_id = ? AND roll_number = ?
+	`), gameID, rollNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := make(map[int]string)
+	for rows.Next() {
+		var playerID int
+		var action string
+		err := rows.Scan(&playerID, &action)
+		if err != nil {
+			return nil, err
+		}
+		actions[playerID] = action
+	}
+
+	return actions, rows.Err()
+}
@@ -0,0 +1,222 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"seesharpsi/stixx_online/events"
+)
+
+// Store keeps games, their players and marks, sessions, the game event log and the archive of finished games.
+// Implementations publish the events they add to a game's log once they are saved.
+type Store interface {
+	// CreateGame creates a waiting game, committing to a new server seed for its dice
+	CreateGame() (*Game, error)
+	GetGame(gameCode string) (*Game, error)
+	GetGameByID(gameID int) (*Game, error)
+	// StartGame moves a waiting game to active, failing with ErrAlreadyStarted otherwise
+	StartGame(gameID int) error
+	// SaveGame writes a change to a game all at once.
+	// It fails with ErrConflict if the game's version changed since it was loaded.
+	SaveGame(change *GameChange) error
+	// GetBotGames returns the IDs of active games with computer players
+	GetBotGames() ([]int, error)
+	// GetIdleGames returns the games in a status where nothing has happened since idleSince, in order of creation.
+	// A game's last activity is its latest event, or its creation if it has none.
+	GetIdleGames(status string, idleSince time.Time) ([]Game, error)
+	// ExpireGame removes a waiting game with its players and sessions, failing with ErrAlreadyStarted once it has started
+	ExpireGame(gameID int) error
+	// ArchiveGame replaces a finished game by its summary in the archive, failing with ErrNotFinished otherwise.
+	// players are how each of its players ended up.
+	ArchiveGame(gameID int, players []ArchivedPlayer) error
+	// GetArchivedGame returns the summary of an archived game
+	GetArchivedGame(gameCode string) (*ArchivedGame, error)
+
+	// JoinGame adds a player to a waiting game, or returns the player of that name to let them rejoin.
+	// It fails with ErrGameFull once the game has maxPlayers players, and with ErrBotSeat for the name of a computer player.
+	// New players mix clientSeed into the game's dice; an empty one is replaced by their seat's default seed.
+	JoinGame(gameCode, playerName, clientSeed string, maxPlayers int) (*Player, error)
+	// AddBot seats a computer player using the named strategy in a game that hasn't started,
+	// failing with ErrGameFull once it has maxPlayers players
+	AddBot(gameCode, strategy string, maxPlayers int) (*Player, error)
+	// GetPlayers returns a game's players in turn order
+	GetPlayers(gameID int) ([]Player, error)
+	// GetClientSeeds returns the client seeds of a game's players in turn order
+	GetClientSeeds(gameID int) ([]string, error)
+
+	// GetPlayerMarks returns a player's marks ordered by color and number
+	GetPlayerMarks(playerID int) ([]PlayerMark, error)
+	// GetWhiteActions returns what each player did with the white sum on a roll, keyed by player ID.
+	// Players who have not acted yet are absent from the map.
+	GetWhiteActions(gameID, rollNumber int) (map[int]string, error)
+	// GetRolls returns a game's dice history in order
+	GetRolls(gameID int) ([]Roll, error)
+	// GetGameEvents returns a game's log in order
+	GetGameEvents(gameID int) ([]events.Event, error)
+
+	CreateSession(session *Session) error
+	// GetSession returns the unexpired session with the given token hash
+	GetSession(tokenHash string) (*Session, error)
+	// RotateSession replaces a session's token, keeping the player and game it belongs to
+	RotateSession(oldTokenHash string, session *Session) error
+	DeleteSession(tokenHash string) error
+
+	Close() error
+}
+
+// GameChange is everything written when a game's state changes
+type GameChange struct {
+	Game         *Game // the new row, with the version it was loaded at; bumped when saved
+	Marks        []PlayerMark
+	Penalties    map[int]int // new penalty counts by player ID
+	WhiteActions []WhiteAction
+	Rolls        []Roll
+	Events       []events.Event // added to the game's log, numbered as they are saved
+}
+
+// Backends lists the storage backends Init accepts
+var Backends = []string{"sqlite", "memory", "postgres"}
+
+// Default is the store used by the package level functions
+var Default Store
+
+// Open opens a storage backend and brings its schema up to date.
+// dsn is the SQLite file or PostgreSQL connection string; the memory backend ignores it.
+func Open(backend, dsn string) (Store, error) {
+	var store *SQLStore
+	var err error
+	switch backend {
+	case "memory":
+		return NewMemory(), nil
+	case "sqlite":
+		store, err = NewSQLite(dsn)
+	case "postgres":
+		store, err = NewPostgres(dsn)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q, choose from %s", backend, strings.Join(Backends, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := store.Migrate()
+	for _, m := range applied {
+		log.Printf("applied migration %d: %s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// Init opens a storage backend as the default store
+func Init(backend, dsn string) error {
+	store, err := Open(backend, dsn)
+	if err != nil {
+		return err
+	}
+	Default = store
+	return nil
+}
+
+// InitDB opens the SQLite database in the working directory as the default store
+func InitDB() error {
+	return Init("sqlite", Path)
+}
+
+func Close() {
+	if Default != nil {
+		Default.Close()
+	}
+}
+
+func CreateGame() (*Game, error) {
+	return Default.CreateGame()
+}
+
+func GetGame(gameCode string) (*Game, error) {
+	return Default.GetGame(gameCode)
+}
+
+func GetGameByID(gameID int) (*Game, error) {
+	return Default.GetGameByID(gameID)
+}
+
+func StartGame(gameID int) error {
+	return Default.StartGame(gameID)
+}
+
+func SaveGame(change *GameChange) error {
+	return Default.SaveGame(change)
+}
+
+func GetBotGames() ([]int, error) {
+	return Default.GetBotGames()
+}
+
+func GetIdleGames(status string, idleSince time.Time) ([]Game, error) {
+	return Default.GetIdleGames(status, idleSince)
+}
+
+func ExpireGame(gameID int) error {
+	return Default.ExpireGame(gameID)
+}
+
+func ArchiveGame(gameID int, players []ArchivedPlayer) error {
+	return Default.ArchiveGame(gameID, players)
+}
+
+func GetArchivedGame(gameCode string) (*ArchivedGame, error) {
+	return Default.GetArchivedGame(gameCode)
+}
+
+func JoinGame(gameCode, playerName, clientSeed string, maxPlayers int) (*Player, error) {
+	return Default.JoinGame(gameCode, playerName, clientSeed, maxPlayers)
+}
+
+func AddBot(gameCode, strategy string, maxPlayers int) (*Player, error) {
+	return Default.AddBot(gameCode, strategy, maxPlayers)
+}
+
+func GetPlayers(gameID int) ([]Player, error) {
+	return Default.GetPlayers(gameID)
+}
+
+func GetClientSeeds(gameID int) ([]string, error) {
+	return Default.GetClientSeeds(gameID)
+}
+
+func GetPlayerMarks(playerID int) ([]PlayerMark, error) {
+	return Default.GetPlayerMarks(playerID)
+}
+
+func GetWhiteActions(gameID, rollNumber int) (map[int]string, error) {
+	return Default.GetWhiteActions(gameID, rollNumber)
+}
+
+func GetRolls(gameID int) ([]Roll, error) {
+	return Default.GetRolls(gameID)
+}
+
+func GetGameEvents(gameID int) ([]events.Event, error) {
+	return Default.GetGameEvents(gameID)
+}
+
+func CreateSession(session *Session) error {
+	return Default.CreateSession(session)
+}
+
+func GetSession(tokenHash string) (*Session, error) {
+	return Default.GetSession(tokenHash)
+}
+
+func RotateSession(oldTokenHash string, session *Session) error {
+	return Default.RotateSession(oldTokenHash, session)
+}
+
+func DeleteSession(tokenHash string) error {
+	return Default.DeleteSession(tokenHash)
+}
@@ -0,0 +1,610 @@
+package db_test
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/dice"
+	"seesharpsi/stixx_online/events"
+)
+
+// TestStores runs the same checks against every storage backend.
+// PostgreSQL is only tested when STIXX_TEST_POSTGRES holds the connection string of a
+// database the test may wipe.
+func TestStores(t *testing.T) {
+	backends := map[string]func(t *testing.T) db.Store{
+		"memory": func(t *testing.T) db.Store {
+			return db.NewMemory()
+		},
+		"sqlite": func(t *testing.T) db.Store {
+			return openStore(t, "sqlite", filepath.Join(t.TempDir(), "qwixx.db"))
+		},
+		"postgres": func(t *testing.T) db.Store {
+			dsn := os.Getenv("STIXX_TEST_POSTGRES")
+			if dsn == "" {
+				t.Skip("set STIXX_TEST_POSTGRES to test against PostgreSQL")
+			}
+			wipePostgres(t, dsn)
+			return openStore(t, "postgres", dsn)
+		},
+	}
+
+	for name, newStore := range backends {
+		t.Run(name, func(t *testing.T) {
+			testStore(t, newStore)
+		})
+	}
+}
+
+func openStore(t *testing.T, backend, dsn string) db.Store {
+	store, err := db.Open(backend, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func wipePostgres(t *testing.T, dsn string) {
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testStore(t *testing.T, newStore func(t *testing.T) db.Store) {
+	t.Run("games", func(t *testing.T) {
+		store := newStore(t)
+
+		game, err := store.CreateGame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(game.GameCode) != 5 || game.Status != "waiting" {
+			t.Errorf("created game %q with status %q, want a 5 character code and waiting", game.GameCode, game.Status)
+		}
+		if game.SeedHash != dice.Commitment(game.ServerSeed) {
+			t.Errorf("seed hash %q doesn't commit to server seed %q", game.SeedHash, game.ServerSeed)
+		}
+
+		byCode, err := store.GetGame(game.GameCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byID, err := store.GetGameByID(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, found := range []*db.Game{byCode, byID} {
+			if found.ID != game.ID || found.GameCode != game.GameCode || found.ServerSeed != game.ServerSeed || found.Version != 0 {
+				t.Errorf("got %+v, want the created game %+v", found, game)
+			}
+		}
+
+		_, err = store.GetGame("NOPE0")
+		if !errors.Is(err, db.ErrGameNotFound) {
+			t.Errorf("getting a missing game: got %v, want %v", err, db.ErrGameNotFound)
+		}
+		_, err = store.GetGameByID(game.ID + 1000)
+		if !errors.Is(err, db.ErrGameNotFound) {
+			t.Errorf("getting a missing game by ID: got %v, want %v", err, db.ErrGameNotFound)
+		}
+	})
+
+	t.Run("bot names", func(t *testing.T) {
+		store := newStore(t)
+		game := createGame(t, store)
+
+		joinGame(t, store, game.GameCode, "alice", "")
+		joinGame(t, store, game.GameCode, "Bot 2 (greedy)", "")
+		bot, err := store.AddBot(game.GameCode, "greedy", 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bot.Name != "Bot 3 (greedy)" {
+			t.Errorf("added bot %q next to a player named after seat 2", bot.Name)
+		}
+	})
+
+	t.Run("players", func(t *testing.T) {
+		store := newStore(t)
+		game := createGame(t, store)
+
+		alice := joinGame(t, store, game.GameCode, "alice", "alice-seed")
+		bob := joinGame(t, store, game.GameCode, "bob", "")
+		if alice.TurnOrder != 0 || bob.TurnOrder != 1 || alice.GameID != game.ID || !alice.IsActive {
+			t.Errorf("joined %+v and %+v, want turns 0 and 1 in game %d", alice, bob, game.ID)
+		}
+
+		again := joinGame(t, store, game.GameCode, "alice", "other-seed")
+		if again.ID != alice.ID {
+			t.Errorf("rejoining gave player %d, want %d", again.ID, alice.ID)
+		}
+
+		_, err := store.JoinGame(game.GameCode, "carol", "not a seed!", 4)
+		if !errors.Is(err, dice.ErrInvalidClientSeed) {
+			t.Errorf("joining with an invalid seed: got %v, want %v", err, dice.ErrInvalidClientSeed)
+		}
+		_, err = store.JoinGame("NOPE0", "carol", "", 4)
+		if !errors.Is(err, db.ErrGameNotFound) {
+			t.Errorf("joining a missing game: got %v, want %v", err, db.ErrGameNotFound)
+		}
+
+		bot, err := store.AddBot(game.GameCode, "greedy", 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bot.Name != "Bot 3 (greedy)" || bot.BotStrategy != "greedy" || bot.TurnOrder != 2 {
+			t.Errorf("added bot %+v", bot)
+		}
+		_, err = store.JoinGame(game.GameCode, "Bot 3 (greedy)", "", 4)
+		if !errors.Is(err, db.ErrBotSeat) {
+			t.Errorf("rejoining as a bot: got %v, want %v", err, db.ErrBotSeat)
+		}
+		joinGame(t, store, game.GameCode, "dave", "")
+		_, err = store.AddBot(game.GameCode, "greedy", 4)
+		if !errors.Is(err, db.ErrGameFull) {
+			t.Errorf("adding a fifth player: got %v, want %v", err, db.ErrGameFull)
+		}
+		_, err = store.JoinGame(game.GameCode, "erin", "", 4)
+		if !errors.Is(err, db.ErrGameFull) {
+			t.Errorf("joining as a fifth player: got %v, want %v", err, db.ErrGameFull)
+		}
+
+		players, err := store.GetPlayers(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, p := range players {
+			names = append(names, p.Name)
+		}
+		if want := []string{"alice", "bob", "Bot 3 (greedy)", "dave"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("players %v, want %v", names, want)
+		}
+
+		seeds, err := store.GetClientSeeds(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"alice-seed"}
+		for seat := 1; seat < 4; seat++ {
+			want = append(want, dice.DefaultClientSeed(game.GameCode, seat, game.SeedHash))
+		}
+		if !reflect.DeepEqual(seeds, want) {
+			t.Errorf("client seeds %q, want alice's seed first and the default seeds of their seats for the others", seeds)
+		}
+
+		botGames, err := store.GetBotGames()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(botGames) != 0 {
+			t.Errorf("bot games before the start %v, want none", botGames)
+		}
+
+		err = store.StartGame(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = store.StartGame(game.ID)
+		if !errors.Is(err, db.ErrAlreadyStarted) {
+			t.Errorf("starting twice: got %v, want %v", err, db.ErrAlreadyStarted)
+		}
+		_, err = store.JoinGame(game.GameCode, "erin", "", 4)
+		if !errors.Is(err, db.ErrAlreadyStarted) {
+			t.Errorf("joining a started game: got %v, want %v", err, db.ErrAlreadyStarted)
+		}
+		_, err = store.AddBot(game.GameCode, "greedy", 4)
+		if !errors.Is(err, db.ErrAlreadyStarted) {
+			t.Errorf("adding a bot to a started game: got %v, want %v", err, db.ErrAlreadyStarted)
+		}
+		joinGame(t, store, game.GameCode, "bob", "")
+
+		botGames, err = store.GetBotGames()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(botGames, []int{game.ID}) {
+			t.Errorf("bot games %v, want [%d]", botGames, game.ID)
+		}
+
+		started, err := store.GetGameByID(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if started.Status != "active" || started.Version != 1 {
+			t.Errorf("started game has status %q and version %d, want active and 1", started.Status, started.Version)
+		}
+	})
+
+	t.Run("saving", func(t *testing.T) {
+		store := newStore(t)
+		game := createGame(t, store)
+		alice := joinGame(t, store, game.GameCode, "alice", "")
+		bob := joinGame(t, store, game.GameCode, "bob", "")
+		err := store.StartGame(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := store.GetGameByID(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved := *loaded
+		saved.DiceRolled = true
+		saved.RollNumber = 1
+		saved.TurnPhase = "colored"
+		saved.WhiteDice1, saved.WhiteDice2, saved.RedDice, saved.BlueDice = 3, 4, 5, 6
+		saved.RedLocked = true
+
+		change := db.GameChange{
+			Game: &saved,
+			Marks: []db.PlayerMark{
+				{PlayerID: alice.ID, Color: "red", Number: 7},
+				{PlayerID: alice.ID, Color: "blue", Number: 12},
+				{PlayerID: bob.ID, Color: "red", Number: 7},
+			},
+			Penalties:    map[int]int{bob.ID: 1},
+			WhiteActions: []db.WhiteAction{{PlayerID: alice.ID, RollNumber: 1, Action: "marked"}, {PlayerID: bob.ID, RollNumber: 1, Action: "passed"}},
+			Rolls:        []db.Roll{{RollNumber: 1, PlayerID: alice.ID, WhiteDice1: 3, WhiteDice2: 4, RedDice: 5, BlueDice: 6, Seed: -42}},
+			Events: []events.Event{
+				{GameID: game.ID, Type: events.Rolled, PlayerID: alice.ID, Dice: []int{3, 4, 5, 0, 0, 6}},
+				{GameID: game.ID, Type: events.Marked, PlayerID: alice.ID, Color: "red", Number: 7, Move: "white"},
+			},
+		}
+		err = store.SaveGame(&change)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved.Version != loaded.Version+1 {
+			t.Errorf("saved game has version %d, want %d", saved.Version, loaded.Version+1)
+		}
+		if change.Events[0].Seq != 4 || change.Events[1].Seq != 5 {
+			t.Errorf("saved events numbered %d and %d, want 4 and 5 after two joins and the start", change.Events[0].Seq, change.Events[1].Seq)
+		}
+
+		stored, err := store.GetGameByID(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !stored.DiceRolled || stored.RollNumber != 1 || stored.TurnPhase != "colored" || !stored.RedLocked ||
+			stored.WhiteDice1 != 3 || stored.BlueDice != 6 || stored.Version != saved.Version || stored.ServerSeed != game.ServerSeed {
+			t.Errorf("stored game %+v doesn't match the saved one %+v", stored, saved)
+		}
+
+		marks, err := store.GetPlayerMarks(alice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(marks) != 2 || marks[0].Color != "blue" || marks[0].Number != 12 || marks[1].Color != "red" || marks[1].Number != 7 {
+			t.Errorf("alice's marks %+v, want blue 12 and red 7", marks)
+		}
+
+		players, err := store.GetPlayers(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if players[0].Penalties != 0 || players[1].Penalties != 1 {
+			t.Errorf("penalties %d and %d, want 0 and 1", players[0].Penalties, players[1].Penalties)
+		}
+
+		actions, err := store.GetWhiteActions(game.ID, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[int]string{alice.ID: "marked", bob.ID: "passed"}; !reflect.DeepEqual(actions, want) {
+			t.Errorf("white actions %v, want %v", actions, want)
+		}
+		actions, err = store.GetWhiteActions(game.ID, 2)
+		if err != nil || len(actions) != 0 {
+			t.Errorf("white actions of a later roll %v, %v, want none", actions, err)
+		}
+
+		rolls, err := store.GetRolls(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rolls) != 1 || rolls[0].GameID != game.ID || rolls[0].Seed != -42 || rolls[0].RedDice != 5 || rolls[0].YellowDice != 0 {
+			t.Errorf("rolls %+v", rolls)
+		}
+
+		evs, err := store.GetGameEvents(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var types []string
+		for i, ev := range evs {
+			types = append(types, ev.Type)
+			if ev.Seq != i+1 || ev.GameID != game.ID {
+				t.Errorf("event %d is numbered %d in game %d", i, ev.Seq, ev.GameID)
+			}
+		}
+		if want := []string{events.PlayerJoined, events.PlayerJoined, events.GameStarted, events.Rolled, events.Marked}; !reflect.DeepEqual(types, want) {
+			t.Errorf("event types %v, want %v", types, want)
+		}
+		if len(evs) == 5 && (evs[0].Name != "alice" || !reflect.DeepEqual(evs[3].Dice, []int{3, 4, 5, 0, 0, 6}) || evs[4].Move != "white") {
+			t.Errorf("events %+v lost their details", evs)
+		}
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		store := newStore(t)
+		game := createGame(t, store)
+		alice := joinGame(t, store, game.GameCode, "alice", "")
+
+		loaded, err := store.GetGameByID(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		first := *loaded
+		first.RollNumber = 1
+		err = store.SaveGame(&db.GameChange{Game: &first, Marks: []db.PlayerMark{{PlayerID: alice.ID, Color: "red", Number: 2}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A change based on the game as it was before the first one was saved
+		stale := *loaded
+		stale.RollNumber = 2
+		err = store.SaveGame(&db.GameChange{Game: &stale, Marks: []db.PlayerMark{{PlayerID: alice.ID, Color: "red", Number: 3}}})
+		if !errors.Is(err, db.ErrConflict) {
+			t.Errorf("saving a stale game: got %v, want %v", err, db.ErrConflict)
+		}
+
+		// A change breaking a unique constraint is not saved at all
+		again := first
+		again.RollNumber = 3
+		err = store.SaveGame(&db.GameChange{
+			Game:   &again,
+			Marks:  []db.PlayerMark{{PlayerID: alice.ID, Color: "red", Number: 4}, {PlayerID: alice.ID, Color: "red", Number: 2}},
+			Events: []events.Event{{GameID: game.ID, Type: events.Penalty, PlayerID: alice.ID}},
+		})
+		if err == nil {
+			t.Error("marking the same number twice was saved")
+		}
+
+		stored, err := store.GetGameByID(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.RollNumber != 1 || stored.Version != first.Version {
+			t.Errorf("game has roll %d and version %d, want only the first change saved", stored.RollNumber, stored.Version)
+		}
+		marks, err := store.GetPlayerMarks(alice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(marks) != 1 || marks[0].Number != 2 {
+			t.Errorf("marks %+v, want only red 2", marks)
+		}
+		evs, err := store.GetGameEvents(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(evs) != 1 {
+			t.Errorf("%d events, want only alice joining", len(evs))
+		}
+	})
+
+	t.Run("cleanup", func(t *testing.T) {
+		store := newStore(t)
+		waiting := createGame(t, store)
+		alice := joinGame(t, store, waiting.GameCode, "alice", "")
+		err := store.CreateSession(&db.Session{TokenHash: "alice", PlayerID: alice.ID, GameCode: waiting.GameCode, CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		active := createGame(t, store)
+		joinGame(t, store, active.GameCode, "bob", "")
+		err = store.StartGame(active.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		finished := createGame(t, store)
+		carol := joinGame(t, store, finished.GameCode, "carol", "")
+		dave := joinGame(t, store, finished.GameCode, "dave", "")
+		err = store.StartGame(finished.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ended, err := store.GetGameByID(finished.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ended.Status, ended.FinishReason, ended.RollNumber = "finished", "four_penalties", 1
+		err = store.SaveGame(&db.GameChange{
+			Game:   ended,
+			Marks:  []db.PlayerMark{{PlayerID: carol.ID, Color: "red", Number: 2}, {PlayerID: carol.ID, Color: "red", Number: 3}},
+			Rolls:  []db.Roll{{RollNumber: 1, PlayerID: carol.ID, WhiteDice1: 1, WhiteDice2: 1, RedDice: 2}},
+			Events: []events.Event{{GameID: finished.ID, Type: events.GameFinished, Reason: "four_penalties"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		later := time.Now().Add(time.Hour)
+		for status, want := range map[string][]int{"waiting": {waiting.ID}, "active": {active.ID}, "finished": {finished.ID}} {
+			idle, err := store.GetIdleGames(status, later)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, g := range idle {
+				ids = append(ids, g.ID)
+			}
+			if !reflect.DeepEqual(ids, want) {
+				t.Errorf("%s games idle an hour from now %v, want %v", status, ids, want)
+			}
+		}
+		idle, err := store.GetIdleGames("waiting", time.Now().Add(-time.Hour))
+		if err != nil || len(idle) != 0 {
+			t.Errorf("waiting games idle for an hour %v, %v, want none", idle, err)
+		}
+
+		err = store.ExpireGame(active.ID)
+		if !errors.Is(err, db.ErrAlreadyStarted) {
+			t.Errorf("expiring an active game: got %v, want %v", err, db.ErrAlreadyStarted)
+		}
+		err = store.ExpireGame(waiting.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = store.GetGame(waiting.GameCode)
+		if !errors.Is(err, db.ErrGameNotFound) {
+			t.Errorf("getting an expired game: got %v, want %v", err, db.ErrGameNotFound)
+		}
+		_, err = store.GetSession("alice")
+		if !errors.Is(err, db.ErrSessionNotFound) {
+			t.Errorf("getting the session of an expired game: got %v, want %v", err, db.ErrSessionNotFound)
+		}
+		players, err := store.GetPlayers(waiting.ID)
+		if err != nil || len(players) != 0 {
+			t.Errorf("players of an expired game %v, %v, want none", players, err)
+		}
+
+		err = store.ArchiveGame(active.ID, nil)
+		if !errors.Is(err, db.ErrNotFinished) {
+			t.Errorf("archiving an active game: got %v, want %v", err, db.ErrNotFinished)
+		}
+		logged, err := store.GetGameEvents(finished.ID)
+		if err != nil || len(logged) == 0 {
+			t.Fatalf("events of a finished game %v, %v", logged, err)
+		}
+		err = store.ArchiveGame(finished.ID, []db.ArchivedPlayer{
+			{Name: "dave", TurnOrder: 1, Penalties: 4, Score: -20, Marks: map[string][]int{}},
+			{Name: "carol", TurnOrder: 0, Score: 3, Marks: map[string][]int{"red": {2, 3}}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = store.GetGameByID(finished.ID)
+		if !errors.Is(err, db.ErrGameNotFound) {
+			t.Errorf("getting an archived game: got %v, want %v", err, db.ErrGameNotFound)
+		}
+		for _, playerID := range []int{carol.ID, dave.ID} {
+			marks, err := store.GetPlayerMarks(playerID)
+			if err != nil || len(marks) != 0 {
+				t.Errorf("marks of an archived game %v, %v, want none", marks, err)
+			}
+		}
+		evs, err := store.GetGameEvents(finished.ID)
+		if err != nil || len(evs) != 0 {
+			t.Errorf("events of an archived game %v, %v, want none", evs, err)
+		}
+
+		archived, err := store.GetArchivedGame(finished.GameCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if archived.FinishReason != "four_penalties" || archived.Rolls != 1 || archived.FinishedAt.IsZero() || archived.FinishedAt.Before(archived.CreatedAt) {
+			t.Errorf("archived game %+v", archived)
+		}
+		if len(archived.Players) != 2 || archived.Players[0].Name != "carol" || archived.Players[0].Score != 3 ||
+			!reflect.DeepEqual(archived.Players[0].Marks, map[string][]int{"red": {2, 3}}) || archived.Players[1].Penalties != 4 {
+			t.Errorf("archived players %+v", archived.Players)
+		}
+		if !reflect.DeepEqual(archived.Events, logged) {
+			t.Errorf("archived events %+v, want the game's log %+v", archived.Events, logged)
+		}
+		_, err = store.GetArchivedGame(active.GameCode)
+		if !errors.Is(err, db.ErrGameNotFound) {
+			t.Errorf("getting a game that isn't archived: got %v, want %v", err, db.ErrGameNotFound)
+		}
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		store := newStore(t)
+		game := createGame(t, store)
+		alice := joinGame(t, store, game.GameCode, "alice", "")
+
+		created := time.Now().UTC().Truncate(time.Second)
+		session := &db.Session{TokenHash: "hash-1", PlayerID: alice.ID, GameCode: game.GameCode, CreatedAt: created, ExpiresAt: created.Add(time.Hour)}
+		err := store.CreateSession(session)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := store.GetSession("hash-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.PlayerID != alice.ID || found.GameCode != game.GameCode || !found.ExpiresAt.Equal(session.ExpiresAt) {
+			t.Errorf("got session %+v, want %+v", found, session)
+		}
+
+		rotated := &db.Session{TokenHash: "hash-2", CreatedAt: created, ExpiresAt: created.Add(2 * time.Hour)}
+		err = store.RotateSession("hash-1", rotated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = store.GetSession("hash-1")
+		if !errors.Is(err, db.ErrSessionNotFound) {
+			t.Errorf("getting a rotated session: got %v, want %v", err, db.ErrSessionNotFound)
+		}
+		found, err = store.GetSession("hash-2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.PlayerID != alice.ID || !found.ExpiresAt.Equal(rotated.ExpiresAt) {
+			t.Errorf("rotated session %+v kept the wrong player or expiry", found)
+		}
+		err = store.RotateSession("hash-1", rotated)
+		if !errors.Is(err, db.ErrSessionNotFound) {
+			t.Errorf("rotating a missing session: got %v, want %v", err, db.ErrSessionNotFound)
+		}
+
+		expired := &db.Session{TokenHash: "hash-3", PlayerID: alice.ID, GameCode: game.GameCode, CreatedAt: created.Add(-2 * time.Hour), ExpiresAt: created.Add(-time.Hour)}
+		err = store.CreateSession(expired)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = store.GetSession("hash-3")
+		if !errors.Is(err, db.ErrSessionNotFound) {
+			t.Errorf("getting an expired session: got %v, want %v", err, db.ErrSessionNotFound)
+		}
+
+		err = store.DeleteSession("hash-2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = store.GetSession("hash-2")
+		if !errors.Is(err, db.ErrSessionNotFound) {
+			t.Errorf("getting a deleted session: got %v, want %v", err, db.ErrSessionNotFound)
+		}
+	})
+}
+
+func createGame(t *testing.T, store db.Store) *db.Game {
+	game, err := store.CreateGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return game
+}
+
+func joinGame(t *testing.T, store db.Store, gameCode, name, clientSeed string) *db.Player {
+	player, err := store.JoinGame(gameCode, name, clientSeed, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return player
+}
@@ -0,0 +1,95 @@
+// Package dice provides the random numbers behind every roll.
+//
+// Each roll's faces are derived from a seed, so recording the seed of a roll
+// is enough to reproduce it exactly: with Faces for seeded dice, and from the
+// game's seeds for provably fair dice, see Fair.
+package dice
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	mathrand "math/rand"
+	"sync"
+)
+
+// Dice rolls the dice for games
+type Dice interface {
+	// Roll returns count faces from 1 to 6 for a roll of a game, and the seed they were derived from
+	Roll(gameID int, rollNumber int, count int) ([]int, int64, error)
+}
+
+// Faces derives count die faces from a seed
+func Faces(seed int64, count int) []int {
+	rng := mathrand.New(mathrand.NewSource(seed))
+	faces := make([]int, count)
+	for i := range faces {
+		faces[i] = rng.Intn(6) + 1
+	}
+	return faces
+}
+
+// Crypto seeds every roll from the operating system's secure random source
+type Crypto struct{}
+
+func (Crypto) Roll(gameID int, rollNumber int, count int) ([]int, int64, error) {
+	var b [8]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return nil, 0, err
+	}
+	seed := int64(binary.BigEndian.Uint64(b[:]))
+	return Faces(seed, count), seed, nil
+}
+
+// Seeded derives every roll's seed from one server seed, the game and the roll number,
+// so each game rolls the same dice whenever it is played with the same seed.
+type Seeded struct {
+	Seed int64
+}
+
+func (d Seeded) Roll(gameID int, rollNumber int, count int) ([]int, int64, error) {
+	var b [24]byte
+	binary.BigEndian.PutUint64(b[0:], uint64(d.Seed))
+	binary.BigEndian.PutUint64(b[8:], uint64(gameID))
+	binary.BigEndian.PutUint64(b[16:], uint64(rollNumber))
+	sum := sha256.Sum256(b[:])
+
+	seed := int64(binary.BigEndian.Uint64(sum[:8]))
+	return Faces(seed, count), seed, nil
+}
+
+// Errors from scripted dice
+var (
+	ErrScriptExhausted = errors.New("scripted dice have no rolls left")
+	ErrScriptTooShort  = errors.New("scripted roll has too few faces")
+)
+
+// Scripted returns rolls given in advance, in order, whatever game they are for.
+// Extra faces in a scripted roll are ignored, which lets a script name a face for
+// every colored die even after some rows are locked. Its rolls have seed 0.
+type Scripted struct {
+	mu    sync.Mutex
+	rolls [][]int
+}
+
+// NewScripted returns dice that roll the given faces, one roll after another
+func NewScripted(rolls ...[]int) *Scripted {
+	return &Scripted{rolls: rolls}
+}
+
+func (d *Scripted) Roll(gameID int, rollNumber int, count int) ([]int, int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.rolls) == 0 {
+		return nil, 0, ErrScriptExhausted
+	}
+	if len(d.rolls[0]) < count {
+		return nil, 0, ErrScriptTooShort
+	}
+	faces := d.rolls[0][:count]
+	d.rolls = d.rolls[1:]
+	return faces, 0, nil
+}
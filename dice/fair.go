@@ -0,0 +1,122 @@
+package dice
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Provably fair dice work by commit and reveal:
+//
+//  1. When a game is created the server picks a secret server seed and publishes
+//     its commitment, the SHA-256 hash of the seed.
+//  2. Every player joins with a client seed of their own choosing. Players who leave it
+//     empty, and computer players, get a seed derived from public data, see DefaultClientSeed.
+//  3. Roll n is derived from HMAC-SHA256 keyed with the server seed over the client
+//     seeds in turn order and the roll number, joined by colons ("seedA:seedB:n").
+//     The faces come straight from the bytes of the HMAC, see FairFaces, so checking
+//     them takes nothing but HMAC-SHA256. The first 8 bytes, read as a big-endian
+//     signed integer, are recorded as the roll's seed.
+//  4. When the game finishes the server seed is revealed, and anyone can check it
+//     against the commitment and recompute every roll.
+//
+// The server can't steer the rolls because it committed to its seed before seeing
+// the client seeds, and players can't predict them without the server seed.
+
+// ErrInvalidClientSeed is returned for a client seed that isn't 1 to 64 letters, digits, '-' or '_'
+var ErrInvalidClientSeed = errors.New("client seed must be 1 to 64 letters, digits, '-' or '_'")
+
+var clientSeedPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// NewSeed returns a fresh random seed as hex, usable as a server or client seed
+func NewSeed() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CheckClientSeed returns ErrInvalidClientSeed if a player can't use seed as their client seed
+func CheckClientSeed(seed string) error {
+	if !clientSeedPattern.MatchString(seed) {
+		return ErrInvalidClientSeed
+	}
+	return nil
+}
+
+// DefaultClientSeed returns the client seed of a player who didn't send one, and of computer players: SHA-256 as hex
+// over the game code, the player's seat in turn order from 0 and the commitment, joined by colons.
+// The server can't choose it freely after seeing the other client seeds, but it is derived from public data
+// and adds no randomness, so the dice are only fair to players who bring a random seed of their own.
+func DefaultClientSeed(gameCode string, seat int, commitment string) string {
+	sum := sha256.Sum256([]byte(gameCode + ":" + strconv.Itoa(seat) + ":" + commitment))
+	return hex.EncodeToString(sum[:])
+}
+
+// Commitment returns the hash a game publishes for its server seed: SHA-256 as hex
+func Commitment(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+// rejectFrom is the first byte value FairFaces skips: 252 is the largest multiple of 6
+// that fits in a byte, so the bytes it keeps give every face the same chance
+const rejectFrom = 252
+
+// FairSeed derives the seed of a roll from the server seed, the client seeds in turn order and the roll number
+func FairSeed(serverSeed string, clientSeeds []string, rollNumber int) int64 {
+	return int64(binary.BigEndian.Uint64(rollHMAC(serverSeed, clientSeeds, rollNumber)[:8]))
+}
+
+// FairFaces derives count die faces of a roll by rejection sampling the bytes of its HMAC.
+// Each byte b below 252 gives the face b%6 + 1 and bigger bytes are skipped. Should the
+// 32 bytes run out, the next 32 are HMAC-SHA256 keyed with the server seed over the last 32.
+func FairFaces(serverSeed string, clientSeeds []string, rollNumber int, count int) []int {
+	block := rollHMAC(serverSeed, clientSeeds, rollNumber)
+	faces := make([]int, 0, count)
+	for {
+		for _, b := range block {
+			if len(faces) == count {
+				return faces
+			}
+			if b < rejectFrom {
+				faces = append(faces, int(b)%6+1)
+			}
+		}
+		if len(faces) == count {
+			return faces
+		}
+		block = hmacSHA256(serverSeed, block)
+	}
+}
+
+// rollHMAC returns the HMAC a roll is derived from
+func rollHMAC(serverSeed string, clientSeeds []string, rollNumber int) []byte {
+	message := strings.Join(append(append([]string(nil), clientSeeds...), strconv.Itoa(rollNumber)), ":")
+	return hmacSHA256(serverSeed, []byte(message))
+}
+
+func hmacSHA256(key string, message []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// Fair rolls a game's provably fair dice
+type Fair struct {
+	ServerSeed  string
+	ClientSeeds []string // in turn order
+}
+
+func (d Fair) Roll(gameID int, rollNumber int, count int) ([]int, int64, error) {
+	seed := FairSeed(d.ServerSeed, d.ClientSeeds, rollNumber)
+	return FairFaces(d.ServerSeed, d.ClientSeeds, rollNumber, count), seed, nil
+}
@@ -0,0 +1,124 @@
+package dice
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// The expected values below were computed outside Go, following the description in fair.go
+// with nothing but HMAC-SHA256
+
+func TestCommitment(t *testing.T) {
+	want := "b3eacd33433b31b5252351032c9b3e7a2e7aa7738d5decdf0dd6c62680853c06"
+	if got := Commitment("server"); got != want {
+		t.Errorf("Commitment(%q) = %s, want %s", "server", got, want)
+	}
+
+	seed, err := NewSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seed) != 64 || seed == other || Commitment(seed) == Commitment(other) {
+		t.Errorf("NewSeed gave %q and %q", seed, other)
+	}
+}
+
+func TestDefaultClientSeed(t *testing.T) {
+	commitment := Commitment("server")
+	want := "b388affa9e8811ddc5c8a2461bf6c068de99c5489e45b4a205413ebbca4a80fc"
+	if got := DefaultClientSeed("ABC12", 1, commitment); got != want {
+		t.Errorf("DefaultClientSeed = %s, want %s", got, want)
+	}
+	if err := CheckClientSeed(want); err != nil {
+		t.Errorf("default seed %s isn't a valid client seed: %s", want, err)
+	}
+	if DefaultClientSeed("ABC12", 2, commitment) == want {
+		t.Error("two seats got the same default seed")
+	}
+}
+
+func TestCheckClientSeed(t *testing.T) {
+	tests := []struct {
+		seed  string
+		valid bool
+	}{
+		{"alice", true},
+		{"Lucky_7-dice", true},
+		{"", false},
+		{"not a seed!", false},
+		{"a:b", false},
+		{string(make([]byte, 65)), false},
+	}
+
+	for _, test := range tests {
+		err := CheckClientSeed(test.seed)
+		if test.valid && err != nil || !test.valid && !errors.Is(err, ErrInvalidClientSeed) {
+			t.Errorf("CheckClientSeed(%q) = %v", test.seed, err)
+		}
+	}
+}
+
+func TestFairFaces(t *testing.T) {
+	clientSeeds := []string{"alice", "bob"}
+
+	tests := []struct {
+		name       string
+		rollNumber int
+		count      int
+		want       []int
+	}{
+		// HMAC c452b04d9eac...: c4 is 196, 196%6 + 1 = 5, and so on
+		{"every byte used", 3, 6, []int{5, 5, 3, 6, 3, 5}},
+		// HMAC 7a5bfd9529856069...: fd is 253 and skipped
+		{"byte skipped", 1, 6, []int{3, 2, 6, 6, 2, 1}},
+		// 40 faces need more than the 32 bytes of the first HMAC
+		{"more faces than bytes", 3, 40, []int{
+			5, 5, 3, 6, 3, 5, 5, 3, 1, 5, 1, 5, 4, 1, 3, 2, 3, 4, 3, 4,
+			4, 3, 6, 1, 4, 3, 3, 6, 5, 1, 1, 4, 2, 2, 5, 3, 6, 2, 2, 6,
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := FairFaces("server", clientSeeds, test.rollNumber, test.count)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("FairFaces(roll %d) = %v, want %v", test.rollNumber, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFairRoll(t *testing.T) {
+	d := Fair{ServerSeed: "server", ClientSeeds: []string{"alice", "bob"}}
+
+	faces, seed, err := d.Roll(1, 3, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{5, 5, 3, 6, 3, 5}; !reflect.DeepEqual(faces, want) {
+		t.Errorf("faces %v, want %v", faces, want)
+	}
+	if want := int64(-4300180846764353944); seed != want || FairSeed(d.ServerSeed, d.ClientSeeds, 3) != want {
+		t.Errorf("seed %d, want %d", seed, want)
+	}
+
+	// Every input changes the roll
+	rolls := [][]int{
+		FairFaces("server", []string{"alice", "bob"}, 3, 6),
+		FairFaces("server2", []string{"alice", "bob"}, 3, 6),
+		FairFaces("server", []string{"bob", "alice"}, 3, 6),
+		FairFaces("server", []string{"alice", "bob"}, 4, 6),
+	}
+	for i := range rolls {
+		for j := i + 1; j < len(rolls); j++ {
+			if reflect.DeepEqual(rolls[i], rolls[j]) {
+				t.Errorf("rolls %d and %d are the same: %v", i, j, rolls[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package events
+
+import (
+	"sort"
+	"sync"
+)
+
+// Event types
+const (
+	PlayerJoined = "player_joined"
+	GameStarted  = "game_started"
+	Rolled       = "roll"
+	Marked       = "mark"
+	Passed       = "pass"
+	Locked       = "lock"
+	Penalty      = "penalty"
+	TurnEnded    = "end_turn" // the active player is done with the roll, the turn moves on once everyone decided on the white sum
+	TurnChanged  = "turn"
+	GameFinished = "finished"
+)
+
+// historySize is how many recent events of each game are kept for clients catching up
+const historySize = 256
+
+// Event describes something that happened in a game.
+// Events are stored in the game's log, which holds enough to rebuild the game from them.
+type Event struct {
+	Seq      int    `json:"seq"` // position in the game's events, starting at 1
+	GameID   int    `json:"game_id"`
+	Type     string `json:"type"`
+	PlayerID int    `json:"player_id,omitempty"` // player who caused the event, 0 if none
+	Color    string `json:"color,omitempty"`     // row for marks and locks
+	Number   int    `json:"number,omitempty"`    // number for marks
+	Move     string `json:"move,omitempty"`      // "white" or "colored" for marks
+	Dice     []int  `json:"dice,omitempty"`      // white, white, red, yellow, green, blue for rolls; 0 when out of play
+	Name     string `json:"name,omitempty"`      // player name when joining
+	Reason   string `json:"reason,omitempty"`    // why the game finished
+}
+
+// Hub fans out events to everyone subscribed to a game
+type Hub struct {
+	mu      sync.Mutex
+	subs    map[int]map[chan Event]struct{}
+	seq     map[int]int
+	history map[int][]Event
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs:    make(map[int]map[chan Event]struct{}),
+		seq:     make(map[int]int),
+		history: make(map[int][]Event),
+	}
+}
+
+// Default is the hub used by the package level functions
+var Default = NewHub()
+
+// Subscribe returns a channel receiving a game's events and a function to stop receiving them
+func (h *Hub) Subscribe(gameID int) (<-chan Event, func()) {
+	ch := make(chan Event, 32)
+
+	h.mu.Lock()
+	if h.subs[gameID] == nil {
+		h.subs[gameID] = make(map[chan Event]struct{})
+	}
+	h.subs[gameID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[gameID][ch]; !ok {
+			return
+		}
+		delete(h.subs[gameID], ch)
+		if len(h.subs[gameID]) == 0 {
+			delete(h.subs, gameID)
+		}
+		close(ch)
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish sends events to every subscriber of their game.
+// Events already numbered by the game's log keep their number, others get the next one.
+// Subscribers that aren't keeping up miss events rather than blocking the publisher.
+func (h *Hub) Publish(evs ...Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, ev := range evs {
+		if ev.Seq == 0 {
+			ev.Seq = h.seq[ev.GameID] + 1
+		}
+		h.seq[ev.GameID] = max(h.seq[ev.GameID], ev.Seq)
+
+		// Saves that finish together can publish out of order
+		history := append(h.history[ev.GameID], ev)
+		sort.SliceStable(history, func(i, j int) bool { return history[i].Seq < history[j].Seq })
+		if len(history) > historySize {
+			history = history[len(history)-historySize:]
+		}
+		h.history[ev.GameID] = history
+
+		for ch := range h.subs[ev.GameID] {
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+}
+
+// Since returns a game's events after seq.
+// It reports false if some of them are no longer kept, for example because the server restarted.
+func (h *Hub) Since(gameID int, seq int) ([]Event, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	latest := h.seq[gameID]
+	if seq > latest {
+		return nil, false
+	}
+	if seq == latest {
+		return nil, true
+	}
+
+	var missed []Event
+	for _, ev := range h.history[gameID] {
+		if ev.Seq > seq {
+			missed = append(missed, ev)
+		}
+	}
+	if len(missed) != latest-seq {
+		return nil, false
+	}
+	return missed, true
+}
+
+// Latest returns the sequence number of a game's last event, 0 if it has none
+func (h *Hub) Latest(gameID int) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.seq[gameID]
+}
+
+// Forget drops what the hub remembers of a game that is gone, once it was expired or archived.
+// Subscribers still connected stay subscribed until they unsubscribe.
+func (h *Hub) Forget(gameID int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.seq, gameID)
+	delete(h.history, gameID)
+}
+
+func Subscribe(gameID int) (<-chan Event, func()) {
+	return Default.Subscribe(gameID)
+}
+
+func Publish(evs ...Event) {
+	Default.Publish(evs...)
+}
+
+func Since(gameID int, seq int) ([]Event, bool) {
+	return Default.Since(gameID, seq)
+}
+
+func Latest(gameID int) int {
+	return Default.Latest(gameID)
+}
+
+func Forget(gameID int) {
+	Default.Forget(gameID)
+}
@@ -0,0 +1,158 @@
+package game
+
+import (
+	"seesharpsi/stixx_online/api"
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/dice"
+)
+
+// FairDice returns a game's provably fair dice, from its server seed and its players' client seeds
+func FairDice(gameID int) (dice.Fair, error) {
+	game, err := db.GetGameByID(gameID)
+	if err != nil {
+		return dice.Fair{}, err
+	}
+
+	clientSeeds, err := db.GetClientSeeds(gameID)
+	if err != nil {
+		return dice.Fair{}, err
+	}
+
+	return dice.Fair{ServerSeed: game.ServerSeed, ClientSeeds: clientSeeds}, nil
+}
+
+// Fairness holds everything needed to check a game's dice.
+// The server seed is only revealed once the game has finished.
+type Fairness struct {
+	Code        string       `json:"code"`
+	Status      string       `json:"status"`
+	SeedHash    string       `json:"seed_hash"`
+	ServerSeed  string       `json:"server_seed,omitempty"`
+	SeedMatches bool         `json:"seed_matches"` // the server seed hashes to SeedHash
+	Players     []FairPlayer `json:"players"`      // in turn order
+	Rolls       []FairRoll   `json:"rolls"`
+	Verified    bool         `json:"verified"` // the seed matches and every roll was recomputed
+}
+
+// FairPlayer is a player's contribution to the dice
+type FairPlayer struct {
+	Name       string `json:"name"`
+	ClientSeed string `json:"client_seed"`
+}
+
+// FairRoll compares a roll with the roll the seeds produce
+type FairRoll struct {
+	Number   int       `json:"number"`
+	Player   string    `json:"player"`
+	Dice     api.Dice  `json:"dice"`
+	Seed     int64     `json:"seed"`               // as recorded
+	Expected *api.Dice `json:"expected,omitempty"` // recomputed, once the server seed is revealed
+	Matches  bool      `json:"matches"`
+}
+
+// CheckFairness recomputes a game's rolls from its seeds, once it has finished
+func CheckFairness(gameCode string) (*Fairness, error) {
+	game, err := db.GetGame(gameCode)
+	if err != nil {
+		return nil, err
+	}
+	players, err := db.GetPlayers(game.ID)
+	if err != nil {
+		return nil, err
+	}
+	clientSeeds, err := db.GetClientSeeds(game.ID)
+	if err != nil {
+		return nil, err
+	}
+	rolls, err := db.GetRolls(game.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Fairness{
+		Code:     game.GameCode,
+		Status:   game.Status,
+		SeedHash: game.SeedHash,
+		Players:  make([]FairPlayer, len(players)),
+		Rolls:    make([]FairRoll, len(rolls)),
+	}
+	names := make(map[int]string)
+	for i, p := range players {
+		f.Players[i] = FairPlayer{Name: p.Name, ClientSeed: clientSeeds[i]}
+		names[p.ID] = p.Name
+	}
+
+	revealed := game.Status == "finished"
+	if revealed {
+		f.ServerSeed = game.ServerSeed
+		f.SeedMatches = dice.Commitment(game.ServerSeed) == game.SeedHash
+	}
+
+	f.Verified = f.SeedMatches
+	for i, r := range rolls {
+		rolled := rollDice(r)
+		roll := FairRoll{
+			Number: r.RollNumber,
+			Player: names[r.PlayerID],
+			Dice:   rolled.API(),
+			Seed:   r.Seed,
+		}
+
+		if revealed {
+			expected := fairRoll(game.ServerSeed, clientSeeds, rolled, r.RollNumber)
+			wire := expected.API()
+			roll.Expected = &wire
+			roll.Matches = expected.Seed == r.Seed && sameFaces(expected, rolled)
+		}
+		f.Verified = f.Verified && roll.Matches
+		f.Rolls[i] = roll
+	}
+
+	return f, nil
+}
+
+// rollDice returns the dice of a recorded roll
+func rollDice(r db.Roll) Dice {
+	d := Dice{White1: r.WhiteDice1, White2: r.WhiteDice2, Colored: make(map[string]int), Seed: r.Seed}
+	colored := map[string]int{"red": r.RedDice, "yellow": r.YellowDice, "green": r.GreenDice, "blue": r.BlueDice}
+	for color, value := range colored {
+		if value != 0 {
+			d.Colored[color] = value
+		}
+	}
+	return d
+}
+
+// fairRoll recomputes a roll from the seeds, for the colored dice that were in play
+func fairRoll(serverSeed string, clientSeeds []string, rolled Dice, rollNumber int) Dice {
+	var inPlay []string
+	for _, color := range Colors {
+		if _, ok := rolled.Colored[color]; ok {
+			inPlay = append(inPlay, color)
+		}
+	}
+
+	faces := dice.FairFaces(serverSeed, clientSeeds, rollNumber, 2+len(inPlay))
+	return facesDice(faces, inPlay, dice.FairSeed(serverSeed, clientSeeds, rollNumber))
+}
+
+// facesDice returns the dice showing faces: the white dice, then the colored dice in play
+func facesDice(faces []int, inPlay []string, seed int64) Dice {
+	d := Dice{White1: faces[0], White2: faces[1], Colored: make(map[string]int), Seed: seed}
+	for i, color := range inPlay {
+		d.Colored[color] = faces[2+i]
+	}
+	return d
+}
+
+func sameFaces(a, b Dice) bool {
+	if a.White1 != b.White1 || a.White2 != b.White2 || len(a.Colored) != len(b.Colored) {
+		return false
+	}
+	for color, value := range a.Colored {
+		if b.Colored[color] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package game
+
+import (
+	"testing"
+
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/dice"
+)
+
+// useMemory makes the test keep its games in memory
+func useMemory(t *testing.T) {
+	previous := db.Default
+	db.Default = db.NewMemory()
+	t.Cleanup(func() { db.Default = previous })
+}
+
+// reseeded records the seeds of the fair dice but derives the faces from the seed alone,
+// like seeded dice do, so its rolls must not verify
+type reseeded struct {
+	fair dice.Fair
+}
+
+func (d reseeded) Roll(gameID int, rollNumber int, count int) ([]int, int64, error) {
+	seed := dice.FairSeed(d.fair.ServerSeed, d.fair.ClientSeeds, rollNumber)
+	return dice.Faces(seed, count), seed, nil
+}
+
+func TestCheckFairness(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   func(t *testing.T, gameID int) dice.Dice // nil rolls the game's fair dice
+		verified bool
+	}{
+		{"fair dice", nil, true},
+		{"seeds of the fair dice with other faces", func(t *testing.T, gameID int) dice.Dice {
+			fair, err := FairDice(gameID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return reseeded{fair}
+		}, false},
+		{"other dice", func(t *testing.T, gameID int) dice.Dice {
+			return dice.Seeded{Seed: 1}
+		}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useMemory(t)
+			g, err := db.CreateGame()
+			if err != nil {
+				t.Fatal(err)
+			}
+			alice, err := db.JoinGame(g.GameCode, "alice", "alice-seed", MaxPlayers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bob, err := db.JoinGame(g.GameCode, "bob", "", MaxPlayers)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.source != nil {
+				DiceSource = test.source(t, g.ID)
+				t.Cleanup(func() { DiceSource = nil })
+			}
+			for _, step := range []error{
+				StartGame(alice.ID, g.ID),
+				EndTurn(alice.ID, g.ID),
+				PassWhite(bob.ID, g.ID),
+				RollDice(bob.ID, g.ID),
+			} {
+				if step != nil {
+					t.Fatal(step)
+				}
+			}
+
+			// The server seed stays secret while the game is played
+			f, err := CheckFairness(g.GameCode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.ServerSeed != "" || f.Verified || len(f.Rolls) != 2 || f.Rolls[0].Expected != nil {
+				t.Fatalf("before the end: %+v", f)
+			}
+			if f.SeedHash != dice.Commitment(g.ServerSeed) || f.Players[0].ClientSeed != "alice-seed" {
+				t.Errorf("commitment %s and client seeds %+v", f.SeedHash, f.Players)
+			}
+
+			err = Abandon(g.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err = CheckFairness(g.GameCode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.ServerSeed != g.ServerSeed || !f.SeedMatches {
+				t.Errorf("revealed %q, matching %v", f.ServerSeed, f.SeedMatches)
+			}
+			if f.Verified != test.verified {
+				t.Errorf("verified %v, want %v", f.Verified, test.verified)
+			}
+			for _, roll := range f.Rolls {
+				if roll.Expected == nil || roll.Matches != test.verified {
+					t.Errorf("roll %d: %+v recomputed as %+v, matching %v", roll.Number, roll.Dice, roll.Expected, roll.Matches)
+				}
+			}
+		})
+	}
+}
@@ -1,15 +1,36 @@
 package game
 
 import (
-	"fmt"
+	"seesharpsi/stixx_online/api"
 	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/dice"
 )
 
+// DiceSource rolls the dice for RollDice instead of the game's provably fair dice when set.
+// The server can swap in seeded or scripted dice to make games reproducible,
+// but their rolls won't verify against the game's seeds.
+var DiceSource dice.Dice
+
 // Row represents the numbers available in each color
 type Row struct {
-	Color   string
-	Numbers []int
-	Locked  bool
+	Color   string `json:"color"`
+	Numbers []int  `json:"numbers"`
+	Locked  bool   `json:"locked"`
+}
+
+// LockNumber returns the rightmost number of the row, which closes the row when marked
+func (r Row) LockNumber() int {
+	return r.Numbers[len(r.Numbers)-1]
+}
+
+// HasLockBonus reports whether the marks include the lock number, earning the lock symbol as an extra mark
+func (r Row) HasLockBonus(marks []int) bool {
+	for _, mark := range marks {
+		if mark == r.LockNumber() {
+			return true
+		}
+	}
+	return false
 }
 
 // GameState represents the current state of a Qwixx game
@@ -17,6 +38,7 @@ type GameState struct {
 	Game    *db.Game
 	Players []db.Player
 	Rows    map[string]Row
+	State   State
 }
 
 // Initialize the rows for Qwixx
@@ -59,414 +81,167 @@ func LoadGameState(gameCode string) (*GameState, error) {
 		return nil, err
 	}
 
-	rows := InitializeRows()
-	redRow := rows["red"]
-	redRow.Locked = game.RedLocked
-	rows["red"] = redRow
-
-	yellowRow := rows["yellow"]
-	yellowRow.Locked = game.YellowLocked
-	rows["yellow"] = yellowRow
-
-	greenRow := rows["green"]
-	greenRow.Locked = game.GreenLocked
-	rows["green"] = greenRow
-
-	blueRow := rows["blue"]
-	blueRow.Locked = game.BlueLocked
-	rows["blue"] = blueRow
+	state, err := LoadState(game)
+	if err != nil {
+		return nil, err
+	}
 
 	return &GameState{
 		Game:    game,
 		Players: players,
-		Rows:    rows,
+		Rows:    state.Rows(),
+		State:   state,
 	}, nil
 }
 
-// GetPlayerMarkedNumbers returns all numbers marked by a player organized by color
-func GetPlayerMarkedNumbers(playerID int) (map[string][]int, error) {
-	marks, err := db.GetPlayerMarks(playerID)
-	if err != nil {
-		return nil, err
-	}
-
-	markedNumbers := make(map[string][]int)
-	for _, mark := range marks {
-		markedNumbers[mark.Color] = append(markedNumbers[mark.Color], mark.Number)
-	}
-
-	return markedNumbers, nil
-}
+// Move represents a possible move in the game.
+// It is defined in package api so clients can use it without linking the server.
+type Move = api.Move
 
-// IsValidMark checks if a player can mark a specific number
-func IsValidMark(playerID int, color string, number int, rows map[string]Row) (bool, error) {
-	row := rows[color]
-
-	// Can't mark in a locked row
-	if row.Locked {
-		return false, nil
-	}
-
-	// Check if number exists in this row
-	validNumber := false
-	numberIndex := -1
-	for i, n := range row.Numbers {
-		if n == number {
-			validNumber = true
-			numberIndex = i
-			break
-		}
-	}
-
-	if !validNumber {
-		return false, nil
-	}
-
-	// Get player's existing marks
-	markedNumbers, err := GetPlayerMarkedNumbers(playerID)
+// StartGame starts a waiting game for its creator and rolls the first dice
+func StartGame(playerID int, gameID int) error {
+	players, err := db.GetPlayers(gameID)
 	if err != nil {
-		return false, err
-	}
-
-	colorMarks := markedNumbers[color]
-
-	// If no marks in this color yet, any number is valid
-	if len(colorMarks) == 0 {
-		// Special rule: can only mark rightmost number (lock) if you have at least 5 marks
-		if numberIndex == len(row.Numbers)-1 {
-			return false, nil
-		}
-		return true, nil
+		return err
 	}
 
-	// Find the rightmost marked number
-	rightmostIndex := -1
-	for _, markedNum := range colorMarks {
-		for i, n := range row.Numbers {
-			if n == markedNum && i > rightmostIndex {
-				rightmostIndex = i
-			}
-		}
+	if len(players) == 0 || players[0].ID != playerID {
+		return ErrNotHost
 	}
-
-	// Can only mark numbers to the right of the rightmost mark
-	if numberIndex <= rightmostIndex {
-		return false, nil
+	if len(players) < 2 {
+		return ErrTooFewPlayers
 	}
 
-	// Special rule for locking: need at least 5 marks to mark the rightmost number
-	if numberIndex == len(row.Numbers)-1 && len(colorMarks) < 5 {
-		return false, nil
+	err = db.StartGame(gameID)
+	if err != nil {
+		return err
 	}
 
-	return true, nil
+	return RollDice(playerID, gameID)
 }
 
-// GetPossibleMoves returns all valid moves for a player given the current dice
-func GetPossibleMoves(playerID int, game *db.Game, isActivePlayer bool) ([]Move, error) {
-	// No moves if dice haven't been rolled
-	if !game.DiceRolled {
-		return []Move{}, nil
-	}
-
-	rows := InitializeRows()
-	redRow := rows["red"]
-	redRow.Locked = game.RedLocked
-	rows["red"] = redRow
-
-	yellowRow := rows["yellow"]
-	yellowRow.Locked = game.YellowLocked
-	rows["yellow"] = yellowRow
-
-	greenRow := rows["green"]
-	greenRow.Locked = game.GreenLocked
-	rows["green"] = greenRow
-
-	blueRow := rows["blue"]
-	blueRow.Locked = game.BlueLocked
-	rows["blue"] = blueRow
-
-	var moves []Move
-
-	// All players can use the sum of white dice (if not already used)
-	if !game.WhiteMarkUsed {
-		whiteSum := game.WhiteDice1 + game.WhiteDice2
-
-		for color := range rows {
-			if valid, _ := IsValidMark(playerID, color, whiteSum, rows); valid {
-				moves = append(moves, Move{
-					PlayerID: playerID,
-					Color:    color,
-					Number:   whiteSum,
-					Type:     "white",
-				})
-			}
+// RollDice rolls the white dice and every colored die still in play for the active player
+func RollDice(playerID int, gameID int) error {
+	source := DiceSource
+	if source == nil {
+		var err error
+		source, err = FairDice(gameID)
+		if err != nil {
+			return err
 		}
 	}
 
-	// Active player can also use white + colored dice (if not already used)
-	if isActivePlayer && !game.ColoredMarkUsed {
-		colorDice := map[string]int{
-			"red":    game.RedDice,
-			"yellow": game.YellowDice,
-			"green":  game.GreenDice,
-			"blue":   game.BlueDice,
+	return update(gameID, func(s State) (State, error) {
+		if s.ActivePlayer().ID != playerID {
+			return s, ErrNotYourTurn
 		}
 
-		for color, colorValue := range colorDice {
-			// White1 + Color
-			sum1 := game.WhiteDice1 + colorValue
-			if valid, _ := IsValidMark(playerID, color, sum1, rows); valid {
-				moves = append(moves, Move{
-					PlayerID: playerID,
-					Color:    color,
-					Number:   sum1,
-					Type:     "colored",
-				})
-			}
-
-			// White2 + Color
-			sum2 := game.WhiteDice2 + colorValue
-			if sum2 != sum1 { // Avoid duplicates
-				if valid, _ := IsValidMark(playerID, color, sum2, rows); valid {
-					moves = append(moves, Move{
-						PlayerID: playerID,
-						Color:    color,
-						Number:   sum2,
-						Type:     "colored",
-					})
-				}
-			}
+		rolled, err := s.RollWith(source, gameID)
+		if err != nil {
+			return s, err
 		}
-	}
-
-	return moves, nil
+		return s.Roll(rolled)
+	})
 }
 
-// Move represents a possible move in the game
-type Move struct {
-	PlayerID int
-	Color    string
-	Number   int
-	Type     string // "white" or "colored"
-}
-
-// MakeMark processes a player marking a number
-func MakeMark(playerID int, color string, number int, gameID int, moveType string) error {
-	// First get the full game state
-	var game db.Game
-	err := db.DB.QueryRow(`SELECT id, game_code, status, red_locked, yellow_locked, green_locked, blue_locked,
-		white_mark_used, colored_mark_used, current_player_index, dice_rolled
-		FROM games WHERE id = ?`, gameID).Scan(&game.ID, &game.GameCode, &game.Status, &game.RedLocked,
-		&game.YellowLocked, &game.GreenLocked, &game.BlueLocked, &game.WhiteMarkUsed, &game.ColoredMarkUsed,
-		&game.CurrentPlayerIndex, &game.DiceRolled)
+// RollWith rolls the white dice and every colored die still in play from source,
+// as the next roll of the game
+func (s State) RollWith(source dice.Dice, gameID int) (Dice, error) {
+	inPlay := s.DiceInPlay()
+	faces, seed, err := source.Roll(gameID, s.RollNumber+1, 2+len(inPlay))
 	if err != nil {
-		return err
-	}
-
-	// Check if dice have been rolled
-	if !game.DiceRolled {
-		return fmt.Errorf("dice have not been rolled")
+		return Dice{}, err
 	}
 
-	// Check if this type of move has already been used
-	if moveType == "white" && game.WhiteMarkUsed {
-		return fmt.Errorf("white dice move already used this turn")
-	}
-	if moveType == "colored" && game.ColoredMarkUsed {
-		return fmt.Errorf("colored dice move already used this turn")
-	}
-
-	// Get current player
-	currentPlayer, err := GetCurrentPlayer(gameID)
-	if err != nil {
-		return err
+	rolled := Dice{White1: faces[0], White2: faces[1], Colored: make(map[string]int), Seed: seed}
+	for i, color := range inPlay {
+		rolled.Colored[color] = faces[2+i]
 	}
+	return rolled, nil
+}
 
-	// Check if it's a colored move and player is not active
-	if moveType == "colored" && currentPlayer.ID != playerID {
-		return fmt.Errorf("only active player can use colored dice")
-	}
+// MakeMark processes a player marking a number
+func MakeMark(playerID int, color string, number int, gameID int, moveType string) error {
+	return update(gameID, func(s State) (State, error) {
+		return s.ApplyMove(Move{PlayerID: playerID, Color: color, Number: number, Type: moveType})
+	})
+}
 
-	rows := InitializeRows()
-	redRow := rows["red"]
-	redRow.Locked = game.RedLocked
-	rows["red"] = redRow
+// PassWhite records that a player is not using the white dice sum on the current roll
+func PassWhite(playerID int, gameID int) error {
+	return update(gameID, func(s State) (State, error) {
+		return s.PassWhite(playerID)
+	})
+}
 
-	yellowRow := rows["yellow"]
-	yellowRow.Locked = game.YellowLocked
-	rows["yellow"] = yellowRow
+// EndTurn finishes the active player's turn, see State.EndTurn
+func EndTurn(playerID int, gameID int) error {
+	return update(gameID, func(s State) (State, error) {
+		if s.ActivePlayer().ID != playerID {
+			return s, ErrNotYourTurn
+		}
+		return s.EndTurn()
+	})
+}
 
-	greenRow := rows["green"]
-	greenRow.Locked = game.GreenLocked
-	rows["green"] = greenRow
+// Abandon finishes a game nobody is playing any more, leaving the scores as they stand
+func Abandon(gameID int) error {
+	return update(gameID, func(s State) (State, error) {
+		if s.Finished {
+			return s, ErrGameFinished
+		}
 
-	blueRow := rows["blue"]
-	blueRow.Locked = game.BlueLocked
-	rows["blue"] = blueRow
+		next := s.Clone()
+		next.Finished = true
+		next.FinishReason = FinishAbandoned
+		return next, nil
+	})
+}
 
-	valid, err := IsValidMark(playerID, color, number, rows)
+// Archive replaces a finished game by a summary of how each of its players ended up
+func Archive(gameID int) error {
+	gameData, err := db.GetGameByID(gameID)
 	if err != nil {
 		return err
 	}
-
-	if !valid {
-		return fmt.Errorf("invalid move")
-	}
-
-	// Mark the number
-	err = db.MarkNumber(playerID, color, number)
+	players, err := db.GetPlayers(gameID)
 	if err != nil {
 		return err
 	}
-
-	// Update which move type was used
-	if moveType == "white" {
-		_, err = db.DB.Exec("UPDATE games SET white_mark_used = TRUE WHERE id = ?", gameID)
-	} else if moveType == "colored" {
-		_, err = db.DB.Exec("UPDATE games SET colored_mark_used = TRUE WHERE id = ?", gameID)
-	}
+	s, err := LoadState(gameData)
 	if err != nil {
 		return err
 	}
 
-	// Check if this locks the row (marking rightmost number)
-	row := rows[color]
-	if number == row.Numbers[len(row.Numbers)-1] {
-		err = db.LockColor(gameID, color)
-		if err != nil {
-			return err
+	archived := make([]db.ArchivedPlayer, len(players))
+	for i, p := range players {
+		archived[i] = db.ArchivedPlayer{
+			Name:        p.Name,
+			TurnOrder:   p.TurnOrder,
+			BotStrategy: p.BotStrategy,
+			Penalties:   p.Penalties,
+			Score:       s.Score(p.ID),
+			Marks:       s.Players[i].Marks,
 		}
 	}
-
-	// Check if turn should automatically end for active player
-	if currentPlayer.ID == playerID {
-		// Active player - check if both moves used
-		var whiteUsed, coloredUsed bool
-		err = db.DB.QueryRow("SELECT white_mark_used, colored_mark_used FROM games WHERE id = ?", gameID).Scan(&whiteUsed, &coloredUsed)
-		if err == nil && whiteUsed && coloredUsed {
-			// Both moves used - automatically end turn
-			// Move to next player and reset turn state
-			err = db.NextTurn(gameID)
-			if err != nil {
-				return fmt.Errorf("failed to end turn: %w", err)
-			}
-
-			// Check if game is finished
-			finished, err := db.IsGameFinished(gameID)
-			if err == nil && finished {
-				db.DB.Exec("UPDATE games SET status = 'finished' WHERE id = ?", gameID)
-			}
-		}
-	}
-
-	return nil
-}
-
-// CalculateScore calculates a player's score
-func CalculateScore(playerID int) (int, error) {
-	marks, err := db.GetPlayerMarks(playerID)
-	if err != nil {
-		return 0, err
-	}
-
-	// Count marks per color
-	colorCounts := make(map[string]int)
-	for _, mark := range marks {
-		colorCounts[mark.Color]++
-	}
-
-	// Score table for Qwixx
-	scoreTable := []int{0, 1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 66, 78}
-
-	totalScore := 0
-	for _, count := range colorCounts {
-		if count < len(scoreTable) {
-			totalScore += scoreTable[count]
-		} else {
-			totalScore += scoreTable[len(scoreTable)-1]
-		}
-	}
-
-	// Get penalties
-	var player db.Player
-	err = db.DB.QueryRow("SELECT penalties FROM players WHERE id = ?", playerID).Scan(&player.Penalties)
-	if err != nil {
-		return 0, err
-	}
-
-	// Subtract 5 points per penalty
-	totalScore -= player.Penalties * 5
-
-	return totalScore, nil
+	return db.ArchiveGame(gameID, archived)
 }
 
 // GetCurrentPlayer returns the current player in a game
 func GetCurrentPlayer(gameID int) (*db.Player, error) {
-	var player db.Player
-	err := db.DB.QueryRow(`
-		SELECT p.id, p.game_id, p.name, p.turn_order, p.joined_at, p.penalties, p.is_active
-		FROM players p
-		JOIN games g ON g.id = p.game_id
-		WHERE g.id = ? AND p.turn_order = g.current_player_index
-	`, gameID).Scan(&player.ID, &player.GameID, &player.Name, &player.TurnOrder,
-		&player.JoinedAt, &player.Penalties, &player.IsActive)
-
+	gameData, err := db.GetGameByID(gameID)
 	if err != nil {
 		return nil, err
 	}
-
-	return &player, nil
-}
-
-// ProcessTurn handles the logic for processing a turn
-func ProcessTurn(gameID int, playerMoves map[int][]Move, skipPenalty bool) error {
-	// Get current player
-	currentPlayer, err := GetCurrentPlayer(gameID)
+	players, err := db.GetPlayers(gameID)
 	if err != nil {
-		return err
-	}
-
-	// Process moves for all players
-	for playerID, moves := range playerMoves {
-		for _, move := range moves {
-			err := MakeMark(playerID, move.Color, move.Number, gameID, move.Type)
-			if err != nil {
-				return err
-			}
-		}
+		return nil, err
 	}
 
-	// If active player didn't make any colored move, they get a penalty
-	if !skipPenalty {
-		activeMoves := playerMoves[currentPlayer.ID]
-		hasColoredMove := false
-		for _, move := range activeMoves {
-			if move.Type == "colored" {
-				hasColoredMove = true
-				break
-			}
+	for _, p := range players {
+		if p.TurnOrder == gameData.CurrentPlayerIndex {
+			return &p, nil
 		}
-
-		if !hasColoredMove {
-			err = db.AddPenalty(currentPlayer.ID)
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	// Check if game is finished
-	finished, err := db.IsGameFinished(gameID)
-	if err != nil {
-		return err
 	}
-
-	if finished {
-		_, err = db.DB.Exec("UPDATE games SET status = 'finished' WHERE id = ?", gameID)
-		return err
-	}
-
-	// Move to next player
-	return db.NextTurn(gameID)
+	return nil, ErrUnknownPlayer
 }
@@ -0,0 +1,205 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/events"
+)
+
+// Replay reconstructs a game's state from its event log alone, by applying the events in order
+func Replay(evs []events.Event) (State, error) {
+	s := NewState(nil)
+	for _, ev := range evs {
+		var err error
+		s, err = s.applyEvent(ev)
+		if err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// applyEvent returns the state after something recorded in the event log happened
+func (s State) applyEvent(ev events.Event) (State, error) {
+	next := s.Clone()
+
+	i := next.playerIndex(ev.PlayerID)
+	if i < 0 && ev.PlayerID != 0 && ev.Type != events.PlayerJoined {
+		return s, fmt.Errorf("event %d: %w", ev.Seq, ErrUnknownPlayer)
+	}
+
+	switch ev.Type {
+	case events.PlayerJoined:
+		next.Players = append(next.Players, PlayerState{ID: ev.PlayerID, Name: ev.Name, Marks: make(map[string][]int)})
+	case events.GameStarted:
+	case events.Rolled:
+		if len(ev.Dice) != 2+len(Colors) {
+			return s, fmt.Errorf("event %d: roll without dice", ev.Seq)
+		}
+		next.Dice = Dice{White1: ev.Dice[0], White2: ev.Dice[1], Colored: make(map[string]int)}
+		for j, color := range Colors {
+			if ev.Dice[2+j] != 0 {
+				next.Dice.Colored[color] = ev.Dice[2+j]
+			}
+		}
+		next.Rolled = true
+		next.RollNumber++
+		next.WhiteActions = make(map[int]string)
+		next.ColoredUsed = false
+		next.Phase = PhaseWhite
+	case events.Marked:
+		next.Players[i].Marks[ev.Color] = append(next.Players[i].Marks[ev.Color], ev.Number)
+		if ev.Move == MoveColored {
+			next.ColoredUsed = true
+			next.Phase = PhaseDone
+		} else {
+			next.WhiteActions[ev.PlayerID] = WhiteMarked
+			next.advanceWhitePhase(ev.PlayerID)
+		}
+	case events.Passed:
+		next.WhiteActions[ev.PlayerID] = WhitePassed
+		next.advanceWhitePhase(ev.PlayerID)
+	case events.TurnEnded:
+		next.Phase = PhaseDone
+	case events.Penalty:
+		next.Players[i].Penalties++
+	case events.Locked:
+		next.Locked[ev.Color] = true
+		delete(next.Dice.Colored, ev.Color)
+	case events.TurnChanged:
+		next.Current = i
+		next.Rolled = false
+		next.ColoredUsed = false
+		next.Phase = ""
+		next.WhiteActions = make(map[int]string)
+	case events.GameFinished:
+		next.Finished = true
+		next.FinishReason = ev.Reason
+	default:
+		return s, fmt.Errorf("event %d: unknown type %q", ev.Seq, ev.Type)
+	}
+
+	return next, nil
+}
+
+// Turn is a game as it stood after one roll was played out
+type Turn struct {
+	Roll   int            // 0 for the game before the first roll
+	State  State          // the game once everything for the roll happened
+	Events []events.Event // what happened, starting with the roll
+}
+
+// Turns replays a game's event log roll by roll.
+// The first turn holds the players joining and the game starting.
+func Turns(evs []events.Event) ([]Turn, error) {
+	turns := []Turn{{State: NewState(nil)}}
+	for _, ev := range evs {
+		current := &turns[len(turns)-1]
+		if ev.Type == events.Rolled {
+			turns = append(turns, Turn{Roll: current.State.RollNumber + 1, State: current.State})
+			current = &turns[len(turns)-1]
+		}
+
+		next, err := current.State.applyEvent(ev)
+		if err != nil {
+			return nil, err
+		}
+		current.State = next
+		current.Events = append(current.Events, ev)
+	}
+	return turns, nil
+}
+
+// Rebuild reconstructs a game from its event log instead of the state stored in its row,
+// in the same form as LoadGameState
+func Rebuild(gameCode string) (*GameState, error) {
+	game, err := db.GetGame(gameCode)
+	if err != nil {
+		return nil, err
+	}
+	players, err := db.GetPlayers(game.ID)
+	if err != nil {
+		return nil, err
+	}
+	evs, err := db.GetGameEvents(game.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := Replay(evs)
+	if err != nil {
+		return nil, err
+	}
+
+	rebuilt := gameRow(*game, state)
+	rebuilt.Status = "waiting"
+	for _, ev := range evs {
+		if ev.Type == events.GameStarted {
+			rebuilt.Status = "active"
+		}
+	}
+	if state.Finished {
+		rebuilt.Status = "finished"
+	}
+	for i := range players {
+		p, _ := state.Player(players[i].ID)
+		players[i].Penalties = p.Penalties
+	}
+
+	return &GameState{
+		Game:    &rebuilt,
+		Players: players,
+		Rows:    state.Rows(),
+		State:   state,
+	}, nil
+}
+
+// ErrNoEventLog is returned when replaying a game archived before archives kept the event log
+var ErrNoEventLog = errors.New("the game was archived without its event log")
+
+// LoadTurns loads a game and replays its event log roll by roll.
+// Archived games are replayed from the log kept in the archive, in a game holding what the summary knows.
+func LoadTurns(gameCode string) (*db.Game, []Turn, error) {
+	game, err := db.GetGame(gameCode)
+	if errors.Is(err, db.ErrGameNotFound) {
+		return loadArchivedTurns(gameCode)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	evs, err := db.GetGameEvents(game.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	turns, err := Turns(evs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return game, turns, nil
+}
+
+func loadArchivedTurns(gameCode string) (*db.Game, []Turn, error) {
+	archived, err := db.GetArchivedGame(gameCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(archived.Events) == 0 {
+		return nil, nil, ErrNoEventLog
+	}
+
+	turns, err := Turns(archived.Events)
+	if err != nil {
+		return nil, nil, err
+	}
+	game := &db.Game{
+		GameCode:     archived.GameCode,
+		Status:       "finished",
+		CreatedAt:    archived.CreatedAt,
+		RollNumber:   archived.Rolls,
+		FinishReason: archived.FinishReason,
+	}
+	return game, turns, nil
+}
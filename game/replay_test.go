@@ -0,0 +1,144 @@
+package game
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/dice"
+)
+
+// TestReplayMatchesState plays a game with random legal actions and checks after every one
+// that replaying the event log gives the game the players see
+func TestReplayMatchesState(t *testing.T) {
+	useMemory(t)
+	DiceSource = dice.Seeded{Seed: 1}
+	t.Cleanup(func() { DiceSource = nil })
+
+	g, err := db.CreateGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var players []*db.Player
+	for _, name := range []string{"alice", "bob", "carol"} {
+		p, err := db.JoinGame(g.GameCode, name, name+"-seed", MaxPlayers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		players = append(players, p)
+	}
+	err = StartGame(players[0].ID, g.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for step := 1; ; step++ {
+		live, err := LoadGameState(g.GameCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkReplay(t, step, live)
+		if live.State.Finished {
+			break
+		}
+		if step > 1000 {
+			t.Fatal("the game didn't finish")
+		}
+
+		actions := legalActions(live.State, g.ID)
+		if len(actions) == 0 {
+			t.Fatalf("step %d: nobody can act", step)
+		}
+		err = actions[rng.Intn(len(actions))]()
+		if err != nil {
+			t.Fatalf("step %d: %s", step, err)
+		}
+	}
+}
+
+// legalActions lists everything the players of a game can do right now
+func legalActions(s State, gameID int) []func() error {
+	active := s.ActivePlayer().ID
+	if !s.Rolled {
+		return []func() error{func() error { return RollDice(active, gameID) }}
+	}
+
+	var actions []func() error
+	for _, p := range s.Players {
+		for _, move := range s.GetPossibleMoves(p.ID) {
+			actions = append(actions, func() error {
+				return MakeMark(move.PlayerID, move.Color, move.Number, gameID, move.Type)
+			})
+		}
+		if _, decided := s.WhiteActions[p.ID]; !decided {
+			actions = append(actions, func() error { return PassWhite(p.ID, gameID) })
+		}
+	}
+	if s.Phase != PhaseDone {
+		actions = append(actions, func() error { return EndTurn(active, gameID) })
+	}
+	return actions
+}
+
+// checkReplay compares a game with the game Replay, Turns and Rebuild make of its event log
+func checkReplay(t *testing.T, step int, live *GameState) {
+	t.Helper()
+	evs, err := db.GetGameEvents(live.Game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, err := Replay(evs)
+	if err != nil {
+		t.Fatalf("step %d: %s", step, err)
+	}
+	if want, got := comparable(live.State), comparable(replayed); !reflect.DeepEqual(got, want) {
+		t.Fatalf("step %d: replayed\n%+v\nwant\n%+v", step, got, want)
+	}
+
+	turns, err := Turns(evs)
+	if err != nil {
+		t.Fatalf("step %d: %s", step, err)
+	}
+	last := turns[len(turns)-1]
+	if last.Roll != live.State.RollNumber || !reflect.DeepEqual(comparable(last.State), comparable(replayed)) {
+		t.Fatalf("step %d: last turn is roll %d, want %d, in the replayed state", step, last.Roll, live.State.RollNumber)
+	}
+
+	rebuilt, err := Rebuild(live.Game.GameCode)
+	if err != nil {
+		t.Fatalf("step %d: %s", step, err)
+	}
+	if rebuilt.Game.Status != live.Game.Status || !reflect.DeepEqual(comparable(rebuilt.State), comparable(live.State)) {
+		t.Fatalf("step %d: rebuilt %s game\n%+v\nwant %s game\n%+v", step, rebuilt.Game.Status, rebuilt.State, live.Game.Status, live.State)
+	}
+	for _, p := range live.State.Players {
+		if got, want := rebuilt.State.GetPossibleMoves(p.ID), live.State.GetPossibleMoves(p.ID); len(got) != len(want) {
+			t.Fatalf("step %d: rebuilt game offers player %d moves %v, want %v", step, p.ID, got, want)
+		}
+	}
+}
+
+// comparable returns a copy of a state with what only differs in how it was stored made equal:
+// marks sorted by number, no unlocked rows and no seed
+func comparable(s State) State {
+	c := s.Clone()
+	c.Dice.Seed = 0
+	for color, locked := range c.Locked {
+		if !locked {
+			delete(c.Locked, color)
+		}
+	}
+	for _, p := range c.Players {
+		for color, marks := range p.Marks {
+			sort.Ints(marks)
+			if len(marks) == 0 {
+				delete(p.Marks, color)
+			}
+		}
+	}
+	return c
+}
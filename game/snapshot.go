@@ -0,0 +1,59 @@
+package game
+
+import (
+	"seesharpsi/stixx_online/api"
+)
+
+// Snapshot returns the game as seen by the given player, including the moves they can make
+func (gs *GameState) Snapshot(playerID int) api.Snapshot {
+	s := gs.State
+
+	snapshot := api.Snapshot{
+		Code:          gs.Game.GameCode,
+		Status:        gs.Game.Status,
+		FinishReason:  gs.Game.FinishReason,
+		Version:       gs.Game.Version,
+		SeedHash:      gs.Game.SeedHash,
+		PlayerID:      playerID,
+		RollNumber:    s.RollNumber,
+		Rolled:        s.Rolled,
+		Phase:         s.Phase,
+		Dice:          s.Dice.API(),
+		Players:       []api.PlayerSnapshot{},
+		PossibleMoves: []api.Move{},
+	}
+	if len(s.Players) > 0 {
+		snapshot.CurrentPlayerID = s.ActivePlayer().ID
+	}
+
+	rows := s.Rows()
+	for _, color := range Colors {
+		snapshot.Rows = append(snapshot.Rows, api.Row(rows[color]))
+	}
+
+	for _, p := range s.Players {
+		snapshot.Players = append(snapshot.Players, api.PlayerSnapshot{
+			ID:          p.ID,
+			Name:        p.Name,
+			Marks:       p.Marks,
+			Penalties:   p.Penalties,
+			Score:       s.Score(p.ID),
+			WhiteAction: s.WhiteActions[p.ID],
+		})
+	}
+
+	if gs.Game.Status == "active" {
+		snapshot.PossibleMoves = append(snapshot.PossibleMoves, s.GetPossibleMoves(playerID)...)
+	}
+
+	return snapshot
+}
+
+// API returns the dice as sent to clients, without the seed
+func (d Dice) API() api.Dice {
+	colored := make(map[string]int, len(d.Colored))
+	for color, value := range d.Colored {
+		colored[color] = value
+	}
+	return api.Dice{White1: d.White1, White2: d.White2, Colored: colored}
+}
@@ -0,0 +1,563 @@
+package game
+
+import (
+	"errors"
+
+	"seesharpsi/stixx_online/api"
+)
+
+// Colors lists the rows of a scoresheet from top to bottom
+var Colors = []string{"red", "yellow", "green", "blue"}
+
+// MaxPlayers is how many players fit in a game
+const MaxPlayers = 4
+
+// Move types, with the values clients see in package api
+const (
+	MoveWhite   = api.MoveWhite
+	MoveColored = api.MoveColored
+)
+
+// White dice actions a player can take on a roll
+const (
+	WhiteMarked = api.WhiteMarked
+	WhitePassed = api.WhitePassed
+)
+
+// Phases of the active player's turn after rolling
+const (
+	PhaseWhite   = api.PhaseWhite   // deciding on the white sum
+	PhaseColored = api.PhaseColored // may mark a white die + colored die combination
+	PhaseDone    = api.PhaseDone    // finished marking, waiting for the turn to end
+)
+
+// Reasons a game finishes
+const (
+	FinishTwoLocks      = api.FinishTwoLocks
+	FinishFourPenalties = api.FinishFourPenalties
+	FinishAbandoned     = api.FinishAbandoned // nobody played for too long, see package janitor
+)
+
+// Score table for Qwixx, indexed by number of marks in a row
+var scoreTable = []int{0, 1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 66, 78}
+
+// Rule violations returned by the State methods
+var (
+	ErrGameFinished     = errors.New("game is finished")
+	ErrNotRolled        = errors.New("dice have not been rolled")
+	ErrAlreadyRolled    = errors.New("dice already rolled this turn")
+	ErrUnknownPlayer    = errors.New("player is not in this game")
+	ErrWhiteUsed        = errors.New("you have already used or passed on the white dice this roll")
+	ErrColoredUsed      = errors.New("colored dice move already used this turn")
+	ErrTurnDone         = errors.New("you have already ended your turn, waiting for the other players")
+	ErrWhiteFirst       = errors.New("mark or pass on the white sum before using a colored die")
+	ErrNotActivePlayer  = errors.New("only active player can use colored dice")
+	ErrNotYourTurn      = errors.New("not your turn")
+	ErrNotStarted       = errors.New("game has not started yet")
+	ErrNotHost          = errors.New("only the game creator can start the game")
+	ErrTooFewPlayers    = errors.New("need at least 2 players to start")
+	ErrInvalidMove      = errors.New("invalid move")
+	ErrUnknownMoveType  = errors.New("unknown move type")
+	ErrInvalidDiceValue = errors.New("dice values must be between 1 and 6")
+)
+
+// Dice holds the values showing after a roll
+type Dice struct {
+	White1  int            `json:"white1"`
+	White2  int            `json:"white2"`
+	Colored map[string]int `json:"colored"`
+	Seed    int64          `json:"-"` // the seed the faces were derived from, recorded with the roll
+}
+
+// WhiteSum returns the sum of the two white dice
+func (d Dice) WhiteSum() int {
+	return d.White1 + d.White2
+}
+
+// PlayerState is a single player's scoresheet
+type PlayerState struct {
+	ID        int
+	Name      string
+	Marks     map[string][]int
+	Penalties int
+}
+
+// State is an in-memory snapshot of a Qwixx game.
+// Its methods implement the rules without touching the database; methods
+// that change the game return a new State and leave the receiver untouched.
+type State struct {
+	Players      []PlayerState
+	Current      int // index into Players of the active player
+	Dice         Dice
+	Rolled       bool
+	RollNumber   int
+	Locked       map[string]bool
+	WhiteActions map[int]string // what each player did with the white sum this roll
+	ColoredUsed  bool
+	Phase        string // active player's turn phase, empty before the roll
+	Finished     bool
+	FinishReason string
+}
+
+// NewState returns the state of a fresh game for the given players in turn order
+func NewState(players []PlayerState) State {
+	s := State{
+		Players:      make([]PlayerState, len(players)),
+		Locked:       make(map[string]bool),
+		WhiteActions: make(map[int]string),
+	}
+	for i, p := range players {
+		s.Players[i] = PlayerState{ID: p.ID, Name: p.Name, Marks: make(map[string][]int)}
+	}
+	return s
+}
+
+// Clone returns a deep copy of the state
+func (s State) Clone() State {
+	c := s
+
+	c.Players = make([]PlayerState, len(s.Players))
+	for i, p := range s.Players {
+		marks := make(map[string][]int, len(p.Marks))
+		for color, numbers := range p.Marks {
+			marks[color] = append([]int(nil), numbers...)
+		}
+		p.Marks = marks
+		c.Players[i] = p
+	}
+
+	c.Dice.Colored = make(map[string]int, len(s.Dice.Colored))
+	for color, value := range s.Dice.Colored {
+		c.Dice.Colored[color] = value
+	}
+
+	c.Locked = make(map[string]bool, len(s.Locked))
+	for color, locked := range s.Locked {
+		c.Locked[color] = locked
+	}
+
+	c.WhiteActions = make(map[int]string, len(s.WhiteActions))
+	for playerID, action := range s.WhiteActions {
+		c.WhiteActions[playerID] = action
+	}
+
+	return c
+}
+
+// Rows returns the scoresheet rows with their locked state
+func (s State) Rows() map[string]Row {
+	rows := InitializeRows()
+	for color, row := range rows {
+		row.Locked = s.Locked[color]
+		rows[color] = row
+	}
+	return rows
+}
+
+// DiceInPlay returns the colors whose dice are still rolled, in row order
+func (s State) DiceInPlay() []string {
+	var colors []string
+	for _, color := range Colors {
+		if !s.Locked[color] {
+			colors = append(colors, color)
+		}
+	}
+	return colors
+}
+
+// ActivePlayer returns the player whose turn it is
+func (s State) ActivePlayer() PlayerState {
+	return s.Players[s.Current]
+}
+
+// Player returns the player with the given ID
+func (s State) Player(playerID int) (PlayerState, bool) {
+	i := s.playerIndex(playerID)
+	if i < 0 {
+		return PlayerState{}, false
+	}
+	return s.Players[i], true
+}
+
+func (s State) playerIndex(playerID int) int {
+	for i, p := range s.Players {
+		if p.ID == playerID {
+			return i
+		}
+	}
+	return -1
+}
+
+// CanMark checks if a player is allowed to mark a number in a row, ignoring the dice
+func (s State) CanMark(playerID int, color string, number int) bool {
+	row, ok := s.Rows()[color]
+	if !ok || row.Locked {
+		return false
+	}
+
+	player, ok := s.Player(playerID)
+	if !ok {
+		return false
+	}
+
+	numberIndex := indexOf(row.Numbers, number)
+	if numberIndex < 0 {
+		return false
+	}
+
+	// Can only mark numbers to the right of the rightmost mark
+	colorMarks := player.Marks[color]
+	for _, marked := range colorMarks {
+		if indexOf(row.Numbers, marked) >= numberIndex {
+			return false
+		}
+	}
+
+	// Special rule for locking: need at least 5 marks to mark the rightmost number
+	if numberIndex == len(row.Numbers)-1 && len(colorMarks) < 5 {
+		return false
+	}
+
+	return true
+}
+
+// GetPossibleMoves returns all valid moves for a player given the current dice
+func (s State) GetPossibleMoves(playerID int) []Move {
+	moves := []Move{}
+	if !s.Rolled || s.Finished {
+		return moves
+	}
+
+	// Each player can use the sum of white dice once per roll
+	if _, acted := s.WhiteActions[playerID]; !acted {
+		whiteSum := s.Dice.WhiteSum()
+		for _, color := range Colors {
+			if s.CanMark(playerID, color, whiteSum) {
+				moves = append(moves, Move{PlayerID: playerID, Color: color, Number: whiteSum, Type: MoveWhite})
+			}
+		}
+	}
+
+	// Active player can also use white + colored dice once per turn, after deciding on the white sum
+	if s.ActivePlayer().ID == playerID && s.Phase == PhaseColored {
+		for _, color := range Colors {
+			colorValue, ok := s.Dice.Colored[color]
+			if !ok {
+				continue
+			}
+
+			sum1 := s.Dice.White1 + colorValue
+			if s.CanMark(playerID, color, sum1) {
+				moves = append(moves, Move{PlayerID: playerID, Color: color, Number: sum1, Type: MoveColored})
+			}
+
+			sum2 := s.Dice.White2 + colorValue
+			if sum2 != sum1 && s.CanMark(playerID, color, sum2) {
+				moves = append(moves, Move{PlayerID: playerID, Color: color, Number: sum2, Type: MoveColored})
+			}
+		}
+	}
+
+	return moves
+}
+
+// Roll returns the state after the active player rolls the given dice
+func (s State) Roll(dice Dice) (State, error) {
+	if s.Finished {
+		return s, ErrGameFinished
+	}
+	if s.Rolled {
+		return s, ErrAlreadyRolled
+	}
+
+	// Dice of locked rows are out of play
+	rolled := Dice{White1: dice.White1, White2: dice.White2, Colored: make(map[string]int), Seed: dice.Seed}
+	for _, color := range s.DiceInPlay() {
+		rolled.Colored[color] = dice.Colored[color]
+	}
+
+	for _, value := range append([]int{rolled.White1, rolled.White2}, colorValues(rolled)...) {
+		if value < 1 || value > 6 {
+			return s, ErrInvalidDiceValue
+		}
+	}
+
+	next := s.Clone()
+	next.Dice = rolled
+	next.Rolled = true
+	next.RollNumber++
+	next.WhiteActions = make(map[int]string)
+	next.ColoredUsed = false
+	next.Phase = PhaseWhite
+	return next, nil
+}
+
+// ApplyMove returns the state after a player marks a number
+func (s State) ApplyMove(move Move) (State, error) {
+	if err := s.checkCanAct(move.PlayerID); err != nil {
+		return s, err
+	}
+
+	switch move.Type {
+	case MoveWhite:
+		if _, acted := s.WhiteActions[move.PlayerID]; acted {
+			return s, ErrWhiteUsed
+		}
+	case MoveColored:
+		if s.ActivePlayer().ID != move.PlayerID {
+			return s, ErrNotActivePlayer
+		}
+		if s.Phase == PhaseWhite {
+			return s, ErrWhiteFirst
+		}
+		if s.ColoredUsed || s.Phase != PhaseColored {
+			return s, ErrColoredUsed
+		}
+	default:
+		return s, ErrUnknownMoveType
+	}
+
+	if !containsMove(s.GetPossibleMoves(move.PlayerID), move) {
+		return s, ErrInvalidMove
+	}
+
+	next := s.Clone()
+	i := next.playerIndex(move.PlayerID)
+	next.Players[i].Marks[move.Color] = append(next.Players[i].Marks[move.Color], move.Number)
+
+	if move.Type == MoveWhite {
+		next.WhiteActions[move.PlayerID] = WhiteMarked
+		next.advanceWhitePhase(move.PlayerID)
+	} else {
+		next.ColoredUsed = true
+		next.Phase = PhaseDone
+	}
+
+	// Marking the rightmost number closes the row once everyone has decided on the white sum, see resolveRoll
+	next.resolveRoll()
+	return next, nil
+}
+
+// PassWhite returns the state after a player declines the white sum this roll
+func (s State) PassWhite(playerID int) (State, error) {
+	if err := s.checkCanAct(playerID); err != nil {
+		return s, err
+	}
+	if _, acted := s.WhiteActions[playerID]; acted {
+		return s, ErrWhiteUsed
+	}
+
+	next := s.Clone()
+	next.WhiteActions[playerID] = WhitePassed
+	next.advanceWhitePhase(playerID)
+	next.resolveRoll()
+	return next, nil
+}
+
+// advanceWhitePhase moves the turn on to the colored phase once the active player has decided on the white sum
+func (s *State) advanceWhitePhase(playerID int) {
+	if s.ActivePlayer().ID == playerID && s.Phase == PhaseWhite {
+		s.Phase = PhaseColored
+	}
+}
+
+// resolveRoll settles what the roll's marks did once every player has decided on the white sum.
+// Rows closed on the roll lock and their dice leave play only then, so every player marking the
+// lock number on the same roll gets it, whoever was first. The game ends once the active player
+// has also finished with the colored dice, so all marks of the roll that triggered the end still count.
+func (s *State) resolveRoll() {
+	if !s.whiteResolved() {
+		return
+	}
+
+	for _, color := range s.PendingLocks() {
+		s.Locked[color] = true
+		delete(s.Dice.Colored, color)
+	}
+
+	reason := s.EndCondition()
+	if reason != "" && s.Phase == PhaseDone {
+		s.Finished = true
+		s.FinishReason = reason
+	}
+}
+
+// PendingLocks returns the rows that were closed on this roll and lock once it is resolved, in row order
+func (s State) PendingLocks() []string {
+	var colors []string
+	rows := s.Rows()
+	for _, color := range Colors {
+		if s.Locked[color] {
+			continue
+		}
+		for _, p := range s.Players {
+			if rows[color].HasLockBonus(p.Marks[color]) {
+				colors = append(colors, color)
+				break
+			}
+		}
+	}
+	return colors
+}
+
+func (s State) whiteResolved() bool {
+	return len(s.WhiteActions) == len(s.Players)
+}
+
+// TurnComplete reports whether the active player is done marking and every player has decided on the white sum
+func (s State) TurnComplete() bool {
+	return !s.Finished && s.Rolled && s.Phase == PhaseDone && s.whiteResolved()
+}
+
+// EndTurnIfComplete ends the turn once every player has finished with the roll,
+// and otherwise returns the state unchanged
+func (s State) EndTurnIfComplete() (State, error) {
+	if s.TurnComplete() {
+		return s.nextTurn(), nil
+	}
+	return s, nil
+}
+
+// ActiveMarked reports whether the active player has marked anything this turn,
+// either the white sum or a colored combination
+func (s State) ActiveMarked() bool {
+	return s.WhiteActions[s.ActivePlayer().ID] == WhiteMarked || s.ColoredUsed
+}
+
+// EndTurn returns the state after the active player says they are done with their turn.
+// Ending the turn counts as passing on the white sum if they haven't decided on it yet.
+// The turn only moves on once every other player has marked or passed too, see EndTurnIfComplete.
+func (s State) EndTurn() (State, error) {
+	if s.Finished {
+		return s, ErrGameFinished
+	}
+	if !s.Rolled {
+		return s, ErrNotRolled
+	}
+	if s.Phase == PhaseDone {
+		return s, ErrTurnDone
+	}
+
+	next := s.Clone()
+	active := next.ActivePlayer().ID
+	if _, acted := next.WhiteActions[active]; !acted {
+		next.WhiteActions[active] = WhitePassed
+	}
+	next.Phase = PhaseDone
+	next.resolveRoll()
+	return next.EndTurnIfComplete()
+}
+
+// nextTurn returns the state after the turn ends and the next player is up.
+// The active player takes a single penalty only if they marked nothing at all this turn.
+func (s State) nextTurn() State {
+	next := s.Clone()
+	if !next.ActiveMarked() {
+		next.Players[next.Current].Penalties++
+	}
+
+	if reason := next.EndCondition(); reason != "" {
+		next.Finished = true
+		next.FinishReason = reason
+		return next
+	}
+
+	// Move to next player and reset turn state
+	next.Current = (next.Current + 1) % len(next.Players)
+	next.Rolled = false
+	next.ColoredUsed = false
+	next.Phase = ""
+	next.WhiteActions = make(map[int]string)
+	return next
+}
+
+// EndCondition returns why the game has to end: 2 colors are locked or a player has 4 penalties.
+// It returns an empty string while the game can go on.
+func (s State) EndCondition() string {
+	lockedCount := 0
+	for _, locked := range s.Locked {
+		if locked {
+			lockedCount++
+		}
+	}
+	if lockedCount >= 2 {
+		return FinishTwoLocks
+	}
+
+	for _, p := range s.Players {
+		if p.Penalties >= 4 {
+			return FinishFourPenalties
+		}
+	}
+	return ""
+}
+
+// Score calculates a player's score
+func (s State) Score(playerID int) int {
+	player, ok := s.Player(playerID)
+	if !ok {
+		return 0
+	}
+
+	// The player who closed a row counts the lock symbol as one more mark
+	rows := s.Rows()
+	totalScore := 0
+	for color, numbers := range player.Marks {
+		count := len(numbers)
+		if rows[color].HasLockBonus(numbers) {
+			count++
+		}
+		totalScore += rowScore(count)
+	}
+
+	// Subtract 5 points per penalty
+	return totalScore - player.Penalties*5
+}
+
+func (s State) checkCanAct(playerID int) error {
+	if s.Finished {
+		return ErrGameFinished
+	}
+	if !s.Rolled {
+		return ErrNotRolled
+	}
+	if s.playerIndex(playerID) < 0 {
+		return ErrUnknownPlayer
+	}
+	return nil
+}
+
+func rowScore(count int) int {
+	if count < len(scoreTable) {
+		return scoreTable[count]
+	}
+	return scoreTable[len(scoreTable)-1]
+}
+
+func colorValues(d Dice) []int {
+	var values []int
+	for _, color := range Colors {
+		if value, ok := d.Colored[color]; ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func containsMove(moves []Move, move Move) bool {
+	for _, m := range moves {
+		if m.Color == move.Color && m.Number == move.Number && m.Type == move.Type {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOf(numbers []int, number int) int {
+	for i, n := range numbers {
+		if n == number {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,539 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// roll returns dice showing the white values and then red, yellow, green and blue
+func roll(white1, white2, red, yellow, green, blue int) Dice {
+	return Dice{White1: white1, White2: white2, Colored: map[string]int{"red": red, "yellow": yellow, "green": green, "blue": blue}}
+}
+
+// newTestState returns a game between alice (1), bob (2) and carol (3) in which it is alice's turn
+func newTestState() State {
+	return NewState([]PlayerState{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}, {ID: 3, Name: "carol"}})
+}
+
+// rolledState returns a new test game after alice rolled a white sum of 7 (3 and 4),
+// a 2 on red, 5 on yellow, 6 on green and 1 on blue
+func rolledState(t *testing.T) State {
+	t.Helper()
+	return must(t)(newTestState().Roll(roll(3, 4, 2, 5, 6, 1)))
+}
+
+// must returns a function failing the test when a state change fails, and returning the new state otherwise
+func must(t *testing.T) func(State, error) State {
+	return func(s State, err error) State {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+}
+
+// withMarks returns the state with a player's marks in one row replaced
+func withMarks(s State, playerID int, color string, numbers ...int) State {
+	next := s.Clone()
+	next.Players[next.playerIndex(playerID)].Marks[color] = numbers
+	return next
+}
+
+func describe(moves []Move) []string {
+	described := []string{}
+	for _, move := range moves {
+		described = append(described, fmt.Sprintf("%s %s %d", move.Type, move.Color, move.Number))
+	}
+	return described
+}
+
+func TestCanMark(t *testing.T) {
+	tests := []struct {
+		name   string
+		marks  []int
+		locked bool
+		color  string
+		number int
+		want   bool
+	}{
+		{"empty row", nil, false, "red", 5, true},
+		{"right of the rightmost mark", []int{2, 5}, false, "red", 6, true},
+		{"left of the rightmost mark", []int{2, 5}, false, "red", 4, false},
+		{"marked already", []int{2, 5}, false, "red", 5, false},
+		{"descending row", []int{12, 10}, false, "green", 9, true},
+		{"descending row, left of the rightmost mark", []int{12, 10}, false, "green", 11, false},
+		{"lock number with 4 marks", []int{2, 3, 4, 5}, false, "red", 12, false},
+		{"lock number with 5 marks", []int{2, 3, 4, 5, 6}, false, "red", 12, true},
+		{"descending lock number with 5 marks", []int{12, 11, 10, 9, 8}, false, "blue", 2, true},
+		{"locked row", nil, true, "red", 5, false},
+		{"number not in the row", nil, false, "red", 13, false},
+		{"unknown color", nil, false, "purple", 5, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := withMarks(newTestState(), 2, test.color, test.marks...)
+			s.Locked[test.color] = test.locked
+			if got := s.CanMark(2, test.color, test.number); got != test.want {
+				t.Errorf("CanMark(%s %d) = %v, want %v", test.color, test.number, got, test.want)
+			}
+		})
+	}
+
+	if newTestState().CanMark(9, "red", 5) {
+		t.Error("a player who isn't in the game can mark")
+	}
+}
+
+func TestGetPossibleMoves(t *testing.T) {
+	white := []string{"white red 7", "white yellow 7", "white green 7", "white blue 7"}
+
+	tests := []struct {
+		name     string
+		state    func(t *testing.T) State
+		playerID int
+		want     []string
+	}{
+		{"before the roll", func(t *testing.T) State { return newTestState() }, 1, []string{}},
+		{"another player deciding on the white sum", rolledState, 2, white},
+		{"active player deciding on the white sum", rolledState, 1, white},
+		{"another player after passing", func(t *testing.T) State {
+			return must(t)(rolledState(t).PassWhite(2))
+		}, 2, []string{}},
+		{"active player after passing", func(t *testing.T) State {
+			return must(t)(rolledState(t).PassWhite(1))
+		}, 1, []string{
+			"colored red 5", "colored red 6", "colored yellow 8", "colored yellow 9",
+			"colored green 9", "colored green 10", "colored blue 4", "colored blue 5",
+		}},
+		{"active player after the colored move", func(t *testing.T) State {
+			s := must(t)(rolledState(t).PassWhite(1))
+			return must(t)(s.ApplyMove(Move{PlayerID: 1, Color: "red", Number: 5, Type: MoveColored}))
+		}, 1, []string{}},
+		{"same sum with both white dice", func(t *testing.T) State {
+			s := must(t)(newTestState().Roll(roll(3, 3, 2, 5, 6, 1)))
+			s = withMarks(s, 1, "yellow", 12)
+			s = withMarks(s, 1, "green", 2)
+			s = withMarks(s, 1, "blue", 2)
+			return must(t)(s.PassWhite(1))
+		}, 1, []string{"colored red 5"}},
+		{"marks narrow the moves", func(t *testing.T) State {
+			s := withMarks(rolledState(t), 2, "red", 8)
+			return withMarks(s, 2, "green", 6)
+		}, 2, []string{"white yellow 7", "white blue 7"}},
+		{"locked row", func(t *testing.T) State {
+			s := newTestState()
+			s.Locked["red"] = true
+			s = must(t)(s.Roll(roll(3, 4, 2, 5, 6, 1)))
+			return must(t)(s.PassWhite(1))
+		}, 1, []string{"colored yellow 8", "colored yellow 9", "colored green 9", "colored green 10", "colored blue 4", "colored blue 5"}},
+		{"finished game", func(t *testing.T) State {
+			s := rolledState(t)
+			s.Finished = true
+			return s
+		}, 2, []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := describe(test.state(t).GetPossibleMoves(test.playerID))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("moves %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestApplyMove(t *testing.T) {
+	s := rolledState(t)
+
+	bob := must(t)(s.ApplyMove(Move{PlayerID: 2, Color: "red", Number: 7, Type: MoveWhite}))
+	if !reflect.DeepEqual(bob.Players[1].Marks["red"], []int{7}) || bob.WhiteActions[2] != WhiteMarked {
+		t.Errorf("after bob's mark: marks %v, white action %q", bob.Players[1].Marks, bob.WhiteActions[2])
+	}
+	if bob.Phase != PhaseWhite {
+		t.Errorf("another player's mark moved the turn on to phase %q", bob.Phase)
+	}
+	if len(s.Players[1].Marks["red"]) != 0 || len(s.WhiteActions) != 0 {
+		t.Error("ApplyMove changed the state it was called on")
+	}
+
+	alice := must(t)(bob.ApplyMove(Move{PlayerID: 1, Color: "blue", Number: 7, Type: MoveWhite}))
+	if alice.Phase != PhaseColored {
+		t.Errorf("after the active player's white mark: phase %q, want %q", alice.Phase, PhaseColored)
+	}
+
+	colored := must(t)(alice.ApplyMove(Move{PlayerID: 1, Color: "red", Number: 6, Type: MoveColored}))
+	if !colored.ColoredUsed || colored.Phase != PhaseDone || !reflect.DeepEqual(colored.Players[0].Marks["red"], []int{6}) {
+		t.Errorf("after the colored mark: colored used %v, phase %q, marks %v", colored.ColoredUsed, colored.Phase, colored.Players[0].Marks)
+	}
+}
+
+func TestApplyMoveErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		state func(t *testing.T) State
+		move  Move
+		want  error
+	}{
+		{"before the roll", func(t *testing.T) State { return newTestState() },
+			Move{PlayerID: 2, Color: "red", Number: 7, Type: MoveWhite}, ErrNotRolled},
+		{"finished game", func(t *testing.T) State {
+			s := rolledState(t)
+			s.Finished = true
+			return s
+		}, Move{PlayerID: 2, Color: "red", Number: 7, Type: MoveWhite}, ErrGameFinished},
+		{"unknown player", rolledState,
+			Move{PlayerID: 9, Color: "red", Number: 7, Type: MoveWhite}, ErrUnknownPlayer},
+		{"white sum twice", func(t *testing.T) State {
+			return must(t)(rolledState(t).ApplyMove(Move{PlayerID: 2, Color: "red", Number: 7, Type: MoveWhite}))
+		}, Move{PlayerID: 2, Color: "yellow", Number: 7, Type: MoveWhite}, ErrWhiteUsed},
+		{"white sum after passing", func(t *testing.T) State {
+			return must(t)(rolledState(t).PassWhite(2))
+		}, Move{PlayerID: 2, Color: "red", Number: 7, Type: MoveWhite}, ErrWhiteUsed},
+		{"colored dice of another player", rolledState,
+			Move{PlayerID: 2, Color: "red", Number: 5, Type: MoveColored}, ErrNotActivePlayer},
+		{"colored dice before the white sum", rolledState,
+			Move{PlayerID: 1, Color: "red", Number: 5, Type: MoveColored}, ErrWhiteFirst},
+		{"colored dice twice", func(t *testing.T) State {
+			s := must(t)(rolledState(t).PassWhite(1))
+			return must(t)(s.ApplyMove(Move{PlayerID: 1, Color: "red", Number: 5, Type: MoveColored}))
+		}, Move{PlayerID: 1, Color: "yellow", Number: 8, Type: MoveColored}, ErrColoredUsed},
+		{"unknown move type", rolledState,
+			Move{PlayerID: 2, Color: "red", Number: 7, Type: "black"}, ErrUnknownMoveType},
+		{"number the dice don't show", rolledState,
+			Move{PlayerID: 2, Color: "red", Number: 8, Type: MoveWhite}, ErrInvalidMove},
+		{"left of a mark", func(t *testing.T) State { return withMarks(rolledState(t), 2, "red", 9) },
+			Move{PlayerID: 2, Color: "red", Number: 7, Type: MoveWhite}, ErrInvalidMove},
+		{"lock number too early", func(t *testing.T) State {
+			s := must(t)(newTestState().Roll(roll(6, 6, 1, 1, 1, 1)))
+			return withMarks(s, 2, "red", 2, 3, 4, 5)
+		}, Move{PlayerID: 2, Color: "red", Number: 12, Type: MoveWhite}, ErrInvalidMove},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := test.state(t)
+			next, err := s.ApplyMove(test.move)
+			if !errors.Is(err, test.want) {
+				t.Fatalf("got %v, want %v", err, test.want)
+			}
+			if !reflect.DeepEqual(next, s) {
+				t.Error("a rejected move changed the state")
+			}
+		})
+	}
+}
+
+func TestLockRow(t *testing.T) {
+	s := must(t)(newTestState().Roll(roll(6, 6, 1, 2, 3, 4)))
+	s = withMarks(s, 2, "red", 2, 3, 4, 5, 6)
+
+	s = must(t)(s.ApplyMove(Move{PlayerID: 2, Color: "red", Number: 12, Type: MoveWhite}))
+	if s.Locked["red"] || !reflect.DeepEqual(s.PendingLocks(), []string{"red"}) {
+		t.Fatalf("before the roll is resolved: locked %v, pending %v", s.Locked, s.PendingLocks())
+	}
+	if _, ok := s.Dice.Colored["red"]; !ok {
+		t.Error("the row's die left play before everyone decided on the white sum")
+	}
+
+	s = must(t)(s.PassWhite(1))
+	s = must(t)(s.PassWhite(3))
+	if !s.Locked["red"] || len(s.PendingLocks()) != 0 {
+		t.Fatalf("once everyone decided: locked %v, pending %v", s.Locked, s.PendingLocks())
+	}
+	if _, ok := s.Dice.Colored["red"]; ok {
+		t.Error("the locked row's die is still in play")
+	}
+	if got := s.Score(2); got != 28 {
+		t.Errorf("score with 6 marks and the lock symbol %d, want 28", got)
+	}
+	if s.CanMark(3, "red", 12) {
+		t.Error("another player can mark a locked row")
+	}
+
+	// The locked row's die stays out of later rolls
+	s = must(t)(s.EndTurn())
+	s = must(t)(s.Roll(roll(1, 1, 6, 6, 6, 6)))
+	if _, ok := s.Dice.Colored["red"]; ok || !reflect.DeepEqual(s.DiceInPlay(), []string{"yellow", "green", "blue"}) {
+		t.Errorf("dice in play after locking red: %v, rolled %v", s.DiceInPlay(), s.Dice.Colored)
+	}
+}
+
+func TestLockRowTogether(t *testing.T) {
+	// alice and bob can both close red with the white 12, whoever marks first
+	for _, order := range [][]int{{1, 2}, {2, 1}} {
+		t.Run(fmt.Sprint(order), func(t *testing.T) {
+			s := must(t)(newTestState().Roll(roll(6, 6, 1, 2, 3, 4)))
+			s = withMarks(s, 1, "red", 2, 3, 4, 5, 6)
+			s = withMarks(s, 2, "red", 2, 3, 4, 5, 6)
+
+			for _, playerID := range order {
+				if !containsMove(s.GetPossibleMoves(playerID), Move{Color: "red", Number: 12, Type: MoveWhite}) {
+					t.Fatalf("player %d can't close red: %v", playerID, describe(s.GetPossibleMoves(playerID)))
+				}
+				s = must(t)(s.ApplyMove(Move{PlayerID: playerID, Color: "red", Number: 12, Type: MoveWhite}))
+			}
+			s = must(t)(s.PassWhite(3))
+
+			if !s.Locked["red"] {
+				t.Fatal("red isn't locked once the roll is resolved")
+			}
+			for _, playerID := range []int{1, 2} {
+				if got := s.Score(playerID); got != 28 {
+					t.Errorf("player %d scores %d, want 28 with the lock symbol", playerID, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPassWhite(t *testing.T) {
+	s := must(t)(rolledState(t).PassWhite(2))
+	if s.WhiteActions[2] != WhitePassed || s.Phase != PhaseWhite {
+		t.Errorf("after bob passed: white action %q, phase %q", s.WhiteActions[2], s.Phase)
+	}
+
+	_, err := s.PassWhite(2)
+	if !errors.Is(err, ErrWhiteUsed) {
+		t.Errorf("passing twice: got %v, want %v", err, ErrWhiteUsed)
+	}
+	_, err = newTestState().PassWhite(2)
+	if !errors.Is(err, ErrNotRolled) {
+		t.Errorf("passing before the roll: got %v, want %v", err, ErrNotRolled)
+	}
+
+	s = must(t)(s.PassWhite(1))
+	if s.Phase != PhaseColored {
+		t.Errorf("after the active player passed: phase %q, want %q", s.Phase, PhaseColored)
+	}
+}
+
+func TestEndTurn(t *testing.T) {
+	tests := []struct {
+		name          string
+		turn          func(t *testing.T, s State) State // alice's turn, which has to end the turn
+		wantPenalties int
+	}{
+		{"nothing marked", func(t *testing.T, s State) State {
+			s = must(t)(s.EndTurn())
+			s = must(t)(s.PassWhite(2))
+			return must(t)(s.PassWhite(3))
+		}, 1},
+		{"white sum marked", func(t *testing.T, s State) State {
+			s = must(t)(s.ApplyMove(Move{PlayerID: 1, Color: "red", Number: 7, Type: MoveWhite}))
+			s = must(t)(s.PassWhite(2))
+			s = must(t)(s.PassWhite(3))
+			return must(t)(s.EndTurn())
+		}, 0},
+		{"colored dice marked", func(t *testing.T, s State) State {
+			s = must(t)(s.PassWhite(1))
+			s = must(t)(s.ApplyMove(Move{PlayerID: 1, Color: "red", Number: 5, Type: MoveColored}))
+			s = must(t)(s.PassWhite(2))
+			return must(t)(s.PassWhite(3))
+		}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := must(t)(test.turn(t, rolledState(t)).EndTurnIfComplete())
+			if s.Current != 1 || s.Rolled || s.Phase != "" || len(s.WhiteActions) != 0 || s.ColoredUsed {
+				t.Errorf("after the turn: current %d, rolled %v, phase %q, white actions %v, colored used %v",
+					s.Current, s.Rolled, s.Phase, s.WhiteActions, s.ColoredUsed)
+			}
+			if got := s.Players[0].Penalties; got != test.wantPenalties {
+				t.Errorf("alice has %d penalties, want %d", got, test.wantPenalties)
+			}
+		})
+	}
+}
+
+func TestEndTurnWaitsForWhiteSum(t *testing.T) {
+	s := must(t)(rolledState(t).EndTurn())
+	if s.Current != 0 || s.Phase != PhaseDone || s.WhiteActions[1] != WhitePassed {
+		t.Errorf("after ending the turn early: current %d, phase %q, white action %q", s.Current, s.Phase, s.WhiteActions[1])
+	}
+	if s.TurnComplete() {
+		t.Error("the turn is complete before bob and carol decided on the white sum")
+	}
+
+	_, err := s.EndTurn()
+	if !errors.Is(err, ErrTurnDone) {
+		t.Errorf("ending the turn twice: got %v, want %v", err, ErrTurnDone)
+	}
+
+	s = must(t)(s.ApplyMove(Move{PlayerID: 2, Color: "red", Number: 7, Type: MoveWhite}))
+	waiting := must(t)(s.EndTurnIfComplete())
+	if !reflect.DeepEqual(waiting, s) {
+		t.Error("the turn ended while carol was still deciding on the white sum")
+	}
+
+	s = must(t)(s.PassWhite(3))
+	if !s.TurnComplete() {
+		t.Fatal("the turn isn't complete once everyone decided")
+	}
+	s = must(t)(s.EndTurnIfComplete())
+	if s.Current != 1 || s.Players[0].Penalties != 1 || s.Players[1].Penalties != 0 {
+		t.Errorf("after the turn: current %d, penalties %d and %d", s.Current, s.Players[0].Penalties, s.Players[1].Penalties)
+	}
+}
+
+func TestEndTurnErrors(t *testing.T) {
+	_, err := newTestState().EndTurn()
+	if !errors.Is(err, ErrNotRolled) {
+		t.Errorf("ending the turn before the roll: got %v, want %v", err, ErrNotRolled)
+	}
+
+	s := rolledState(t)
+	s.Finished = true
+	_, err = s.EndTurn()
+	if !errors.Is(err, ErrGameFinished) {
+		t.Errorf("ending the turn of a finished game: got %v, want %v", err, ErrGameFinished)
+	}
+}
+
+func TestGameEnd(t *testing.T) {
+	t.Run("fourth penalty", func(t *testing.T) {
+		s := rolledState(t)
+		s.Players[0].Penalties = 3
+		s = must(t)(s.EndTurn())
+		s = must(t)(s.PassWhite(2))
+		s = must(t)(s.PassWhite(3))
+		s = must(t)(s.EndTurnIfComplete())
+		if !s.Finished || s.FinishReason != FinishFourPenalties || s.Players[0].Penalties != 4 {
+			t.Errorf("finished %v (%q) with %d penalties", s.Finished, s.FinishReason, s.Players[0].Penalties)
+		}
+		if s.Current != 0 {
+			t.Error("the turn moved on after the game ended")
+		}
+
+		_, err := s.PassWhite(2)
+		if !errors.Is(err, ErrGameFinished) {
+			t.Errorf("playing on: got %v, want %v", err, ErrGameFinished)
+		}
+	})
+
+	// The game ends once the roll is resolved: everyone decided on the white sum and alice on the colored dice
+	secondLock := func(t *testing.T) State {
+		s := newTestState()
+		s.Locked["yellow"] = true
+		s = must(t)(s.Roll(roll(6, 6, 1, 1, 1, 1)))
+		s = withMarks(s, 2, "red", 2, 3, 4, 5, 6)
+		s = withMarks(s, 3, "red", 2, 3, 4, 5, 6)
+		return must(t)(s.ApplyMove(Move{PlayerID: 2, Color: "red", Number: 12, Type: MoveWhite}))
+	}
+	finishedWithRed := func(t *testing.T, s State) {
+		t.Helper()
+		if !s.Finished || s.FinishReason != FinishTwoLocks || !s.Locked["red"] {
+			t.Errorf("finished %v (%q) with locked rows %v", s.Finished, s.FinishReason, s.Locked)
+		}
+		if got := s.Score(3); got != 28 {
+			t.Errorf("carol, who closed red too, scores %d, want 28", got)
+		}
+	}
+
+	t.Run("second lock, white sum resolved first", func(t *testing.T) {
+		s := secondLock(t)
+		s = must(t)(s.PassWhite(1))
+		if s.Finished {
+			t.Fatal("the game ended before carol decided on the white sum")
+		}
+
+		s = must(t)(s.ApplyMove(Move{PlayerID: 3, Color: "red", Number: 12, Type: MoveWhite}))
+		if s.Finished || !s.Locked["red"] {
+			t.Fatalf("before alice used the colored dice: finished %v with locked rows %v", s.Finished, s.Locked)
+		}
+
+		// alice still gets her colored die, though not the locked red one
+		if containsMove(s.GetPossibleMoves(1), Move{Color: "red", Number: 7, Type: MoveColored}) {
+			t.Error("alice can use the die of a locked row")
+		}
+		s = must(t)(s.ApplyMove(Move{PlayerID: 1, Color: "green", Number: 7, Type: MoveColored}))
+		finishedWithRed(t, s)
+	})
+
+	t.Run("second lock, colored dice decided first", func(t *testing.T) {
+		s := secondLock(t)
+		s = must(t)(s.EndTurn())
+		if s.Finished || s.Locked["red"] {
+			t.Fatalf("before carol decided on the white sum: finished %v with locked rows %v", s.Finished, s.Locked)
+		}
+
+		s = must(t)(s.ApplyMove(Move{PlayerID: 3, Color: "red", Number: 12, Type: MoveWhite}))
+		s = must(t)(s.EndTurnIfComplete())
+		finishedWithRed(t, s)
+		if s.Players[0].Penalties != 0 {
+			t.Error("alice took a penalty for the roll that ended the game")
+		}
+	})
+}
+
+func TestEndCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		locked    []string
+		penalties int
+		want      string
+	}{
+		{"nothing yet", nil, 0, ""},
+		{"one locked row", []string{"red"}, 0, ""},
+		{"two locked rows", []string{"red", "blue"}, 0, FinishTwoLocks},
+		{"three penalties", nil, 3, ""},
+		{"four penalties", nil, 4, FinishFourPenalties},
+		{"both", []string{"red", "blue"}, 4, FinishTwoLocks},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestState()
+			for _, color := range test.locked {
+				s.Locked[color] = true
+			}
+			s.Players[2].Penalties = test.penalties
+			if got := s.EndCondition(); got != test.want {
+				t.Errorf("EndCondition() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		marks     map[string][]int
+		locked    []string
+		penalties int
+		want      int
+	}{
+		{"nothing marked", nil, nil, 0, 0},
+		{"one row", map[string][]int{"red": {2, 3, 4}}, nil, 0, 6},
+		{"every row", map[string][]int{"red": {2}, "yellow": {2, 3}, "green": {12, 11, 10}, "blue": {12, 11, 10, 9}}, nil, 0, 1 + 3 + 6 + 10},
+		{"penalties", map[string][]int{"red": {2, 3, 4}}, nil, 2, 6 - 10},
+		{"lock symbol", map[string][]int{"red": {2, 3, 4, 5, 6, 12}}, []string{"red"}, 0, 28},
+		{"locked by someone else", map[string][]int{"blue": {12, 11}}, []string{"blue"}, 0, 3},
+		{"full row", map[string][]int{"green": {12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2}}, []string{"green"}, 0, 78},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestState()
+			for color, numbers := range test.marks {
+				s = withMarks(s, 3, color, numbers...)
+			}
+			for _, color := range test.locked {
+				s.Locked[color] = true
+			}
+			s.Players[2].Penalties = test.penalties
+			if got := s.Score(3); got != test.want {
+				t.Errorf("Score() = %d, want %d", got, test.want)
+			}
+		})
+	}
+
+	if got := newTestState().Score(9); got != 0 {
+		t.Errorf("score of a player who isn't in the game %d, want 0", got)
+	}
+}
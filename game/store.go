@@ -0,0 +1,262 @@
+package game
+
+import (
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/events"
+)
+
+// LoadState reads a game and its players, marks and white dice actions from the database
+func LoadState(game *db.Game) (State, error) {
+	players, err := db.GetPlayers(game.ID)
+	if err != nil {
+		return State{}, err
+	}
+
+	// The white dice actions of a roll are over once the turn has moved on
+	whiteActions := make(map[int]string)
+	if game.DiceRolled {
+		whiteActions, err = db.GetWhiteActions(game.ID, game.RollNumber)
+		if err != nil {
+			return State{}, err
+		}
+	}
+
+	s := State{
+		Players:    make([]PlayerState, len(players)),
+		Current:    game.CurrentPlayerIndex,
+		Rolled:     game.DiceRolled,
+		RollNumber: game.RollNumber,
+		Dice: Dice{
+			White1:  game.WhiteDice1,
+			White2:  game.WhiteDice2,
+			Colored: make(map[string]int),
+		},
+		Locked: map[string]bool{
+			"red":    game.RedLocked,
+			"yellow": game.YellowLocked,
+			"green":  game.GreenLocked,
+			"blue":   game.BlueLocked,
+		},
+		WhiteActions: whiteActions,
+		ColoredUsed:  game.ColoredMarkUsed,
+		Phase:        game.TurnPhase,
+		Finished:     game.Status == "finished",
+		FinishReason: game.FinishReason,
+	}
+
+	// A colored die stored as 0 is out of play
+	colorDice := map[string]int{
+		"red":    game.RedDice,
+		"yellow": game.YellowDice,
+		"green":  game.GreenDice,
+		"blue":   game.BlueDice,
+	}
+	for color, value := range colorDice {
+		if value != 0 {
+			s.Dice.Colored[color] = value
+		}
+	}
+
+	for i, p := range players {
+		marks, err := db.GetPlayerMarks(p.ID)
+		if err != nil {
+			return State{}, err
+		}
+
+		player := PlayerState{
+			ID:        p.ID,
+			Name:      p.Name,
+			Marks:     make(map[string][]int),
+			Penalties: p.Penalties,
+		}
+		for _, mark := range marks {
+			player.Marks[mark.Color] = append(player.Marks[mark.Color], mark.Number)
+		}
+		s.Players[i] = player
+	}
+
+	return s, nil
+}
+
+// SaveState writes the changes a game went through back to the store all at once,
+// and adds what happened to the game's event log. steps are the states the game passed through
+// after before, ending with its new state.
+// It fails with db.ErrConflict if someone else saved the game after it was loaded.
+func SaveState(game *db.Game, before State, steps ...State) error {
+	after := before
+	if len(steps) > 0 {
+		after = steps[len(steps)-1]
+	}
+
+	var evs []events.Event
+	prev := before
+	for _, step := range steps {
+		evs = append(evs, changeEvents(game.ID, prev, step)...)
+		prev = step
+	}
+
+	saved := gameRow(*game, after)
+	change := db.GameChange{Game: &saved, Penalties: make(map[int]int), Events: evs}
+
+	// New marks and penalties
+	for i, p := range after.Players {
+		old := before.Players[i]
+		for color, numbers := range p.Marks {
+			for _, number := range numbers[len(old.Marks[color]):] {
+				change.Marks = append(change.Marks, db.PlayerMark{PlayerID: p.ID, Color: color, Number: number})
+			}
+		}
+
+		if p.Penalties != old.Penalties {
+			change.Penalties[p.ID] = p.Penalties
+		}
+	}
+
+	// White dice actions, which are cleared again when the change ends the turn
+	prev = before
+	for _, step := range steps {
+		for playerID, action := range step.WhiteActions {
+			if prev.RollNumber == step.RollNumber && prev.WhiteActions[playerID] == action {
+				continue
+			}
+			change.WhiteActions = append(change.WhiteActions, db.WhiteAction{PlayerID: playerID, RollNumber: step.RollNumber, Action: action})
+		}
+		prev = step
+	}
+
+	// New rolls for the dice history
+	if after.RollNumber != before.RollNumber {
+		change.Rolls = append(change.Rolls, db.Roll{
+			RollNumber: after.RollNumber,
+			PlayerID:   after.ActivePlayer().ID,
+			WhiteDice1: after.Dice.White1,
+			WhiteDice2: after.Dice.White2,
+			RedDice:    after.Dice.Colored["red"],
+			YellowDice: after.Dice.Colored["yellow"],
+			GreenDice:  after.Dice.Colored["green"],
+			BlueDice:   after.Dice.Colored["blue"],
+			Seed:       after.Dice.Seed,
+		})
+	}
+
+	err := db.SaveGame(&change)
+	if err != nil {
+		return err
+	}
+
+	*game = saved
+	return nil
+}
+
+// gameRow returns a game's database row updated to a state
+func gameRow(game db.Game, s State) db.Game {
+	game.CurrentPlayerIndex = s.Current
+	game.DiceRolled = s.Rolled
+	game.RollNumber = s.RollNumber
+	game.ColoredMarkUsed = s.ColoredUsed
+	game.TurnPhase = s.Phase
+	game.WhiteDice1 = s.Dice.White1
+	game.WhiteDice2 = s.Dice.White2
+	game.RedDice = s.Dice.Colored["red"]
+	game.YellowDice = s.Dice.Colored["yellow"]
+	game.GreenDice = s.Dice.Colored["green"]
+	game.BlueDice = s.Dice.Colored["blue"]
+	game.RedLocked = s.Locked["red"]
+	game.YellowLocked = s.Locked["yellow"]
+	game.GreenLocked = s.Locked["green"]
+	game.BlueLocked = s.Locked["blue"]
+	if s.Finished {
+		game.Status = "finished"
+		game.FinishReason = s.FinishReason
+	}
+
+	return game
+}
+
+// changeEvents describes what happened between two states of a game
+func changeEvents(gameID int, before, after State) []events.Event {
+	var evs []events.Event
+
+	if after.RollNumber != before.RollNumber {
+		d := after.Dice
+		evs = append(evs, events.Event{
+			GameID:   gameID,
+			Type:     events.Rolled,
+			PlayerID: after.ActivePlayer().ID,
+			Dice:     []int{d.White1, d.White2, d.Colored["red"], d.Colored["yellow"], d.Colored["green"], d.Colored["blue"]},
+		})
+	}
+
+	for i, p := range after.Players {
+		old := before.Players[i]
+
+		// Once the active player has decided on the white sum, their marks use a colored die
+		moveType := MoveWhite
+		if before.ActivePlayer().ID == p.ID && before.Phase == PhaseColored {
+			moveType = MoveColored
+		}
+
+		for _, color := range Colors {
+			for _, number := range p.Marks[color][len(old.Marks[color]):] {
+				evs = append(evs, events.Event{GameID: gameID, Type: events.Marked, PlayerID: p.ID, Color: color, Number: number, Move: moveType})
+			}
+		}
+		if after.WhiteActions[p.ID] == WhitePassed && before.WhiteActions[p.ID] != WhitePassed {
+			evs = append(evs, events.Event{GameID: gameID, Type: events.Passed, PlayerID: p.ID})
+		}
+		if p.Penalties > old.Penalties {
+			evs = append(evs, events.Event{GameID: gameID, Type: events.Penalty, PlayerID: p.ID})
+		}
+	}
+
+	// Ending the turn is only implied by the phase when no colored mark finished it
+	if after.Phase == PhaseDone && before.Phase != PhaseDone && after.ColoredUsed == before.ColoredUsed {
+		evs = append(evs, events.Event{GameID: gameID, Type: events.TurnEnded, PlayerID: after.ActivePlayer().ID})
+	}
+
+	for _, color := range Colors {
+		if after.Locked[color] && !before.Locked[color] {
+			evs = append(evs, events.Event{GameID: gameID, Type: events.Locked, Color: color})
+		}
+	}
+
+	if after.Current != before.Current {
+		evs = append(evs, events.Event{GameID: gameID, Type: events.TurnChanged, PlayerID: after.ActivePlayer().ID})
+	}
+
+	if after.Finished && !before.Finished {
+		evs = append(evs, events.Event{GameID: gameID, Type: events.GameFinished, Reason: after.FinishReason})
+	}
+
+	return evs
+}
+
+// update loads a game, applies a change to its state and saves the result,
+// ending the turn once everyone has finished with the roll
+func update(gameID int, change func(State) (State, error)) error {
+	game, err := db.GetGameByID(gameID)
+	if err != nil {
+		return err
+	}
+	if game.Status == "waiting" {
+		return ErrNotStarted
+	}
+
+	before, err := LoadState(game)
+	if err != nil {
+		return err
+	}
+
+	acted, err := change(before)
+	if err != nil {
+		return err
+	}
+
+	// Everyone has finished with the roll - automatically end turn
+	after, err := acted.EndTurnIfComplete()
+	if err != nil {
+		return err
+	}
+
+	return SaveState(game, before, acted, after)
+}
@@ -4,4 +4,8 @@ go 1.23.3
 
 require github.com/a-h/templ v0.3.906
 
-require github.com/mattn/go-sqlite3 v1.14.28 // indirect
+require github.com/mattn/go-sqlite3 v1.14.28
+
+require github.com/coder/websocket v1.8.14
+
+require github.com/lib/pq v1.12.3
@@ -0,0 +1,214 @@
+// Package janitor cleans up after games nobody plays any more. It deletes lobbies that were
+// never started, ends games that stalled and moves old finished games to the archive.
+package janitor
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/events"
+	"seesharpsi/stixx_online/game"
+)
+
+// Config says how long games may go without any events before the janitor deals with them.
+// A zero duration turns that step off.
+type Config struct {
+	ExpireWaiting   time.Duration // waiting games are deleted with their players and sessions
+	AbandonActive   time.Duration // active games are finished as abandoned
+	ArchiveFinished time.Duration // finished games are replaced by a summary in the archive
+	Interval        time.Duration // time between runs, 0 to only run when asked
+}
+
+// DefaultConfig is the configuration of the default janitor
+var DefaultConfig = Config{
+	ExpireWaiting:   24 * time.Hour,
+	AbandonActive:   7 * 24 * time.Hour,
+	ArchiveFinished: 30 * 24 * time.Hour,
+	Interval:        10 * time.Minute,
+}
+
+// What the janitor does to games
+const (
+	ActionExpired   = "expired"
+	ActionAbandoned = "abandoned"
+	ActionArchived  = "archived"
+)
+
+// now returns the current time, replaced in tests
+var now = time.Now
+
+// historySize is how many recent actions are kept for the admin endpoint
+const historySize = 200
+
+// Action is something the janitor did to a game, or failed to do
+type Action struct {
+	Time     time.Time `json:"time"`
+	Action   string    `json:"action"`              // expired, abandoned or archived
+	GameCode string    `json:"game_code,omitempty"` // empty when looking for idle games failed
+	Error    string    `json:"error,omitempty"`
+}
+
+// Run sums up one pass of the janitor over the games
+type Run struct {
+	Started   time.Time `json:"started"`
+	Finished  time.Time `json:"finished"`
+	Expired   int       `json:"expired"`
+	Abandoned int       `json:"abandoned"`
+	Archived  int       `json:"archived"`
+	Errors    int       `json:"errors"`
+}
+
+// Status is the janitor's configuration and what it did lately
+type Status struct {
+	Running         bool     `json:"running"`        // whether it runs on its own every interval
+	ExpireWaiting   string   `json:"expire_waiting"` // durations such as "24h0m0s", "0s" when the step is off
+	AbandonActive   string   `json:"abandon_active"`
+	ArchiveFinished string   `json:"archive_finished"`
+	Interval        string   `json:"interval"`
+	LastRun         *Run     `json:"last_run,omitempty"`
+	Recent          []Action `json:"recent"` // oldest first
+}
+
+// Janitor runs the cleanup, keeping track of what it did
+type Janitor struct {
+	config Config
+	runMu  sync.Mutex // held during a run, so runs never overlap
+
+	mu      sync.Mutex
+	running bool
+	lastRun *Run
+	recent  []Action
+}
+
+func New(config Config) *Janitor {
+	return &Janitor{config: config}
+}
+
+// Default is the janitor used by the package level functions
+var Default = New(DefaultConfig)
+
+// Start runs the janitor right away and then every interval in the background.
+// Calling it again, or without an interval, does nothing.
+func (j *Janitor) Start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.running || j.config.Interval <= 0 {
+		return
+	}
+	j.running = true
+
+	go func() {
+		for {
+			j.Run()
+			time.Sleep(j.config.Interval)
+		}
+	}()
+}
+
+// Run makes one pass over the games and returns what it did
+func (j *Janitor) Run() Run {
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+
+	run := Run{Started: now().UTC()}
+	j.sweep(&run, "waiting", j.config.ExpireWaiting, ActionExpired, &run.Expired, forgetting(db.ExpireGame))
+	j.sweep(&run, "active", j.config.AbandonActive, ActionAbandoned, &run.Abandoned, game.Abandon)
+	j.sweep(&run, "finished", j.config.ArchiveFinished, ActionArchived, &run.Archived, forgetting(game.Archive))
+	run.Finished = now().UTC()
+
+	if run.Expired+run.Abandoned+run.Archived+run.Errors > 0 {
+		log.Printf("janitor: expired %d, abandoned %d and archived %d games with %d errors\n",
+			run.Expired, run.Abandoned, run.Archived, run.Errors)
+	}
+
+	j.mu.Lock()
+	j.lastRun = &run
+	j.mu.Unlock()
+	return run
+}
+
+// sweep applies an action to every game in a status that has been idle for longer than idle
+func (j *Janitor) sweep(run *Run, status string, idle time.Duration, action string, count *int, apply func(gameID int) error) {
+	if idle <= 0 {
+		return
+	}
+
+	games, err := db.GetIdleGames(status, now().Add(-idle))
+	if err != nil {
+		j.record(run, Action{Action: action, Error: err.Error()})
+		return
+	}
+
+	for _, g := range games {
+		err := apply(g.ID)
+		if err != nil {
+			j.record(run, Action{Action: action, GameCode: g.GameCode, Error: err.Error()})
+			continue
+		}
+		*count++
+		j.record(run, Action{Action: action, GameCode: g.GameCode})
+	}
+}
+
+// forgetting wraps an action that removes a game, so the event hub also drops its recent events
+func forgetting(apply func(gameID int) error) func(gameID int) error {
+	return func(gameID int) error {
+		err := apply(gameID)
+		if err == nil {
+			events.Forget(gameID)
+		}
+		return err
+	}
+}
+
+// record logs an action and keeps it for Status
+func (j *Janitor) record(run *Run, a Action) {
+	a.Time = now().UTC()
+	if a.Error != "" {
+		run.Errors++
+		log.Printf("janitor: %s %s failed: %s\n", a.Action, a.GameCode, a.Error)
+	} else {
+		log.Printf("janitor: %s game %s\n", a.Action, a.GameCode)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.recent = append(j.recent, a)
+	if len(j.recent) > historySize {
+		j.recent = j.recent[len(j.recent)-historySize:]
+	}
+}
+
+// Status returns the janitor's configuration and its recent activity
+func (j *Janitor) Status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	status := Status{
+		Running:         j.running,
+		ExpireWaiting:   j.config.ExpireWaiting.String(),
+		AbandonActive:   j.config.AbandonActive.String(),
+		ArchiveFinished: j.config.ArchiveFinished.String(),
+		Interval:        j.config.Interval.String(),
+		Recent:          append([]Action{}, j.recent...),
+	}
+	if j.lastRun != nil {
+		lastRun := *j.lastRun
+		status.LastRun = &lastRun
+	}
+	return status
+}
+
+func Start() {
+	Default.Start()
+}
+
+func RunNow() Run {
+	return Default.Run()
+}
+
+func CurrentStatus() Status {
+	return Default.Status()
+}
@@ -0,0 +1,104 @@
+package janitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/events"
+	"seesharpsi/stixx_online/game"
+)
+
+// newGame creates a game with two players, started if start is set
+func newGame(t *testing.T, start bool) *db.Game {
+	t.Helper()
+	g, err := db.CreateGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := db.JoinGame(g.GameCode, "alice", "", game.MaxPlayers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.JoinGame(g.GameCode, "bob", "", game.MaxPlayers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start {
+		err = game.StartGame(alice.ID, g.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g
+}
+
+func TestRun(t *testing.T) {
+	previousStore, previousHub, previousNow := db.Default, events.Default, now
+	db.Default, events.Default = db.NewMemory(), events.NewHub()
+	t.Cleanup(func() { db.Default, events.Default, now = previousStore, previousHub, previousNow })
+
+	waiting := newGame(t, false)
+	active := newGame(t, true)
+	finished := newGame(t, true)
+	err := game.Abandon(finished.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := New(Config{ExpireWaiting: 24 * time.Hour, AbandonActive: 7 * 24 * time.Hour, ArchiveFinished: 30 * 24 * time.Hour})
+	start := time.Now()
+	runs := []struct {
+		after time.Duration
+		want  Run
+	}{
+		{time.Hour, Run{}},
+		{25 * time.Hour, Run{Expired: 1}},
+		{8 * 24 * time.Hour, Run{Abandoned: 1}},
+		// Abandoning a game is its last event, so the game abandoned above has been idle for as long as the other
+		{31 * 24 * time.Hour, Run{Archived: 2}},
+		{32 * 24 * time.Hour, Run{}},
+	}
+	for _, run := range runs {
+		now = func() time.Time { return start.Add(run.after) }
+		got := j.Run()
+		if got.Expired != run.want.Expired || got.Abandoned != run.want.Abandoned || got.Archived != run.want.Archived || got.Errors != 0 {
+			t.Errorf("after %s: got %+v, want %+v", run.after, got, run.want)
+		}
+		if !got.Started.Equal(now().UTC()) {
+			t.Errorf("after %s: started at %s, want %s", run.after, got.Started, now().UTC())
+		}
+	}
+
+	for _, g := range []*db.Game{waiting, active, finished} {
+		_, err := db.GetGame(g.GameCode)
+		if !errors.Is(err, db.ErrGameNotFound) {
+			t.Errorf("game %s is still there: %v", g.GameCode, err)
+		}
+		if events.Latest(g.ID) != 0 {
+			t.Errorf("the hub still remembers %d events of game %s", events.Latest(g.ID), g.GameCode)
+		}
+	}
+
+	// Archived games can still be replayed
+	for _, g := range []*db.Game{active, finished} {
+		archived, turns, err := game.LoadTurns(g.GameCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		last := turns[len(turns)-1].State
+		if archived.Status != "finished" || !last.Finished || last.FinishReason != game.FinishAbandoned || len(last.Players) != 2 {
+			t.Errorf("replayed archived game %s as %+v, ending in %+v", g.GameCode, archived, last)
+		}
+	}
+
+	status := j.Status()
+	if len(status.Recent) != 4 || status.Recent[0].Action != ActionExpired || status.Recent[0].GameCode != waiting.GameCode ||
+		status.Recent[1].Action != ActionAbandoned || status.Recent[1].GameCode != active.GameCode {
+		t.Errorf("recent actions %+v", status.Recent)
+	}
+	if status.LastRun == nil || !status.LastRun.Started.Equal(start.Add(32*24*time.Hour).UTC()) {
+		t.Errorf("last run %+v", status.LastRun)
+	}
+}
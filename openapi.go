@@ -0,0 +1,281 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"seesharpsi/stixx_online/api"
+	"seesharpsi/stixx_online/backup"
+	"seesharpsi/stixx_online/events"
+	"seesharpsi/stixx_online/game"
+	"seesharpsi/stixx_online/janitor"
+)
+
+// Route describes one route registered by the server, for the OpenAPI document
+type Route struct {
+	Method      string // empty for routes answering any method
+	Path        string
+	Summary     string
+	Auth        bool     // needs a session cookie or bearer token
+	Admin       bool     // needs the server's admin token as a bearer token
+	Query       []string // optional query parameters
+	Form        []string // form fields of HTML routes
+	Request     any      // JSON request body, nil if none
+	Status      int      // status of a successful response
+	Response    any      // JSON response body, nil if the route doesn't answer with JSON
+	ContentType string   // content type of non-JSON responses
+}
+
+// Pattern returns the http.ServeMux pattern the route is registered under
+func (r Route) Pattern() string {
+	if r.Method == "" {
+		return r.Path
+	}
+	return r.Method + " " + r.Path
+}
+
+// Routes lists every route of the server
+var Routes = []Route{
+	{Path: "/", Summary: "Landing page", Status: http.StatusOK, ContentType: "text/html"},
+	{Path: "/static/{file}", Summary: "Static assets", Status: http.StatusOK, ContentType: "application/octet-stream"},
+	{Path: "/test", Summary: "Test page", Status: http.StatusOK, ContentType: "text/html"},
+
+	// HTML game routes, driven by htmx
+	{Method: "POST", Path: "/create-game", Summary: "Create a game and join it with a client seed for the dice, redirecting to its lobby", Form: []string{"name", "clientSeed"}, Status: http.StatusOK, ContentType: "text/html"},
+	{Method: "POST", Path: "/join-game", Summary: "Join a game with a client seed for the dice, redirecting to its lobby", Form: []string{"name", "gameCode", "clientSeed"}, Status: http.StatusOK, ContentType: "text/html"},
+	{Method: "GET", Path: "/lobby/{gameCode}", Summary: "Lobby page", Auth: true, Status: http.StatusOK, ContentType: "text/html"},
+	{Method: "POST", Path: "/add-bot/{gameCode}", Summary: "Seat a computer player, for the game's creator", Auth: true, Form: []string{"strategy"}, Status: http.StatusNoContent},
+	{Method: "POST", Path: "/start-game/{gameCode}", Summary: "Start the game, redirecting to the game page", Auth: true, Status: http.StatusOK},
+	{Method: "GET", Path: "/game/{gameCode}", Summary: "Game page", Auth: true, Status: http.StatusOK, ContentType: "text/html"},
+	{Method: "GET", Path: "/events/{gameCode}", Summary: "Server-sent events carrying re-rendered page fragments", Auth: true, Status: http.StatusOK, ContentType: "text/event-stream"},
+	{Method: "GET", Path: "/ws/game/{gameCode}", Summary: "WebSocket game protocol, see ClientMessage and ServerMessage", Auth: true, Query: []string{"since"}, Status: http.StatusSwitchingProtocols},
+	{Method: "POST", Path: "/roll-dice/{gameCode}", Summary: "Roll the dice", Auth: true, Status: http.StatusNoContent},
+	{Method: "POST", Path: "/make-move", Summary: "Mark a number", Auth: true, Form: []string{"color", "number", "type"}, Status: http.StatusNoContent},
+	{Method: "POST", Path: "/pass-white/{gameCode}", Summary: "Pass on the white sum", Auth: true, Status: http.StatusNoContent},
+	{Method: "POST", Path: "/end-turn/{gameCode}", Summary: "End the turn", Auth: true, Status: http.StatusNoContent},
+	{Method: "POST", Path: "/leave-game", Summary: "Log out of the game, redirecting to the landing page", Status: http.StatusOK},
+	{Method: "GET", Path: "/verify/{gameCode}", Summary: "Page for checking a game's provably fair dice", Status: http.StatusOK, ContentType: "text/html"},
+	{Method: "GET", Path: "/replay/{gameCode}", Summary: "Page stepping through a finished game roll by roll", Query: []string{"turn"}, Status: http.StatusOK, ContentType: "text/html"},
+
+	// JSON API
+	{Method: "GET", Path: "/api/openapi.json", Summary: "This document", Status: http.StatusOK, ContentType: "application/json"},
+	{Method: "POST", Path: "/api/v1/games", Summary: "Create a game and join it. Send a random client_seed; without one the player's seed is derived from public data", Request: api.PlayerRequest{}, Status: http.StatusCreated, Response: api.JoinResponse{}},
+	{Method: "POST", Path: "/api/v1/games/{gameCode}/players", Summary: "Join a game, or rejoin it under the same name. Send a random client_seed; without one the player's seed is derived from public data", Request: api.PlayerRequest{}, Status: http.StatusOK, Response: api.JoinResponse{}},
+	{Method: "POST", Path: "/api/v1/games/{gameCode}/start", Summary: "Start the game and roll the first dice", Auth: true, Status: http.StatusOK, Response: api.Snapshot{}},
+	{Method: "GET", Path: "/api/v1/games/{gameCode}", Summary: "Get the game", Auth: true, Status: http.StatusOK, Response: api.Snapshot{}},
+	{Method: "GET", Path: "/api/v1/games/{gameCode}/moves", Summary: "List your legal moves", Auth: true, Status: http.StatusOK, Response: api.MovesResponse{}},
+	{Method: "GET", Path: "/api/v1/games/{gameCode}/fairness", Summary: "Get the dice commitment and rolls, with the server seed once the game has finished", Status: http.StatusOK, Response: game.Fairness{}},
+	{Method: "POST", Path: "/api/v1/games/{gameCode}/roll", Summary: "Roll the dice", Auth: true, Status: http.StatusOK, Response: api.Snapshot{}},
+	{Method: "POST", Path: "/api/v1/games/{gameCode}/marks", Summary: "Mark a number", Auth: true, Request: api.MarkRequest{}, Status: http.StatusOK, Response: api.Snapshot{}},
+	{Method: "POST", Path: "/api/v1/games/{gameCode}/pass", Summary: "Pass on the white sum", Auth: true, Status: http.StatusOK, Response: api.Snapshot{}},
+	{Method: "POST", Path: "/api/v1/games/{gameCode}/end-turn", Summary: "End the turn", Auth: true, Status: http.StatusOK, Response: api.Snapshot{}},
+
+	// Admin API, for whoever runs the server
+	{Method: "GET", Path: "/api/v1/admin/janitor", Summary: "Get the janitor's settings and what it did lately", Admin: true, Status: http.StatusOK, Response: janitor.Status{}},
+	{Method: "POST", Path: "/api/v1/admin/janitor/run", Summary: "Clean up idle games right away", Admin: true, Status: http.StatusOK, Response: janitor.Run{}},
+	{Method: "GET", Path: "/api/v1/admin/backups", Summary: "List the database snapshots and the backup schedule", Admin: true, Status: http.StatusOK, Response: backup.Inventory{}},
+	{Method: "POST", Path: "/api/v1/admin/backups", Summary: "Snapshot the database right away", Admin: true, Status: http.StatusCreated, Response: backup.File{}},
+	{Method: "GET", Path: "/api/v1/admin/backups/{name}", Summary: "Download a database snapshot", Admin: true, Status: http.StatusOK, ContentType: "application/vnd.sqlite3"},
+}
+
+// socketMessages are documented as schemas although no route returns them directly
+var socketMessages = []any{api.ClientMessage{}, api.ServerMessage{}, events.Event{}}
+
+var pathParam = regexp.MustCompile(`{(\w+)}`)
+
+// OpenAPI returns the OpenAPI 3 document describing the routes, ready to be encoded as JSON
+func OpenAPI(routes []Route) map[string]any {
+	schemas := make(map[string]any)
+	paths := make(map[string]any)
+
+	for _, route := range routes {
+		item, ok := paths[route.Path].(map[string]any)
+		if !ok {
+			item = make(map[string]any)
+			paths[route.Path] = item
+		}
+
+		method := strings.ToLower(route.Method)
+		if method == "" {
+			method = "get"
+		}
+		item[method] = operation(route, schemas)
+	}
+
+	for _, message := range socketMessages {
+		schemaOf(reflect.TypeOf(message), schemas)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Stixx Online",
+			"version": "1",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+				"cookie": map[string]any{"type": "apiKey", "in": "cookie", "name": "session"},
+				"admin":  map[string]any{"type": "http", "scheme": "bearer", "description": "the token the server was started with as -admin-token"},
+			},
+		},
+	}
+}
+
+func operation(route Route, schemas map[string]any) map[string]any {
+	op := map[string]any{"summary": route.Summary}
+
+	var params []any
+	for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		params = append(params, map[string]any{
+			"name": match[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+	for _, name := range route.Query {
+		params = append(params, map[string]any{
+			"name": name, "in": "query", "schema": map[string]any{"type": "string"},
+		})
+	}
+	if params != nil {
+		op["parameters"] = params
+	}
+
+	if route.Auth {
+		op["security"] = []any{
+			map[string]any{"bearer": []any{}},
+			map[string]any{"cookie": []any{}},
+		}
+	}
+	if route.Admin {
+		op["security"] = []any{map[string]any{"admin": []any{}}}
+	}
+
+	if route.Request != nil {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": schemaOf(reflect.TypeOf(route.Request), schemas)},
+			},
+		}
+	} else if route.Form != nil {
+		properties := make(map[string]any)
+		for _, field := range route.Form {
+			properties[field] = map[string]any{"type": "string"}
+		}
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/x-www-form-urlencoded": map[string]any{
+					"schema": map[string]any{"type": "object", "properties": properties},
+				},
+			},
+		}
+	}
+
+	success := map[string]any{"description": http.StatusText(route.Status)}
+	switch {
+	case route.Response != nil:
+		success["content"] = map[string]any{
+			"application/json": map[string]any{"schema": schemaOf(reflect.TypeOf(route.Response), schemas)},
+		}
+	case route.ContentType != "":
+		success["content"] = map[string]any{
+			route.ContentType: map[string]any{"schema": map[string]any{"type": "string"}},
+		}
+	}
+	responses := map[string]any{strconv.Itoa(route.Status): success}
+
+	// The JSON API and the WebSocket explain their failures
+	if route.Response != nil || route.Status == http.StatusSwitchingProtocols {
+		responses["default"] = map[string]any{
+			"description": "Error",
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": schemaOf(reflect.TypeOf(api.ErrorResponse{}), schemas)},
+			},
+		}
+	}
+	op["responses"] = responses
+
+	return op
+}
+
+// schemaOf returns the schema of a Go type as encoding/json writes it.
+// Named structs are added to schemas and referenced.
+func schemaOf(t reflect.Type, schemas map[string]any) map[string]any {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem(), schemas)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		ref := map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := schemas[t.Name()]; ok {
+			return ref
+		}
+		// Claim the name before looking at the fields, in case the type refers to itself
+		schemas[t.Name()] = nil
+
+		properties := make(map[string]any)
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, omitempty, ok := jsonName(field)
+			if !ok {
+				continue
+			}
+			properties[name] = schemaOf(field.Type, schemas)
+			if !omitempty {
+				required = append(required, name)
+			}
+		}
+
+		schema := map[string]any{"type": "object", "properties": properties}
+		if required != nil {
+			sort.Strings(required)
+			schema["required"] = required
+		}
+		schemas[t.Name()] = schema
+		return ref
+	}
+	return map[string]any{}
+}
+
+// jsonName returns the name encoding/json uses for a struct field and whether it is omitted when empty
+func jsonName(field reflect.StructField) (string, bool, bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(options, "omitempty"), true
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"seesharpsi/stixx_online/api"
+	"seesharpsi/stixx_online/backup"
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/dice"
+	"seesharpsi/stixx_online/game"
+	"seesharpsi/stixx_online/janitor"
+)
+
+// patternRecorder collects the patterns add_routes registers
+type patternRecorder []string
+
+func (p *patternRecorder) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	*p = append(*p, pattern)
+}
+
+func TestOpenAPIDescribesEveryRoute(t *testing.T) {
+	var registered patternRecorder
+	add_routes(&registered)
+
+	described := make(map[string]bool)
+	for _, route := range Routes {
+		if described[route.Pattern()] {
+			t.Errorf("route %q is described twice", route.Pattern())
+		}
+		described[route.Pattern()] = true
+	}
+
+	for _, pattern := range registered {
+		if !described[pattern] {
+			t.Errorf("route %q is registered in add_routes but missing from Routes", pattern)
+		}
+		delete(described, pattern)
+	}
+	for pattern := range described {
+		t.Errorf("route %q is described in Routes but not registered in add_routes", pattern)
+	}
+}
+
+// TestOpenAPIDescribesFormFields checks that the form fields of each route are the ones its handler reads,
+// by finding the handlers in add_routes and their r.FormValue calls in the server's source
+func TestOpenAPIDescribesFormFields(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	funcs := make(map[string]*ast.FuncDecl)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+				funcs[fn.Name.Name] = fn
+			}
+		}
+	}
+
+	// Handlers by pattern, from the mux.HandleFunc calls in add_routes
+	handlers := make(map[string]string)
+	ast.Inspect(funcs["add_routes"], func(n ast.Node) bool {
+		if call, ok := calledMethod(n, "HandleFunc"); ok && len(call.Args) == 2 {
+			pattern, _ := stringLiteral(call.Args[0])
+			if handler, ok := call.Args[1].(*ast.Ident); ok {
+				handlers[pattern] = handler.Name
+			}
+		}
+		return true
+	})
+
+	for _, route := range Routes {
+		handler, ok := funcs[handlers[route.Pattern()]]
+		if !ok {
+			t.Errorf("can't find the handler of %q", route.Pattern())
+			continue
+		}
+
+		var read []string
+		ast.Inspect(handler, func(n ast.Node) bool {
+			if call, ok := calledMethod(n, "FormValue"); ok && len(call.Args) == 1 {
+				if field, ok := stringLiteral(call.Args[0]); ok {
+					read = append(read, field)
+				}
+			}
+			return true
+		})
+
+		documented := append([]string(nil), route.Form...)
+		sort.Strings(read)
+		sort.Strings(documented)
+		if strings.Join(read, ",") != strings.Join(documented, ",") {
+			t.Errorf("%s reads the form fields %v, but Routes lists %v", route.Pattern(), read, documented)
+		}
+	}
+}
+
+// calledMethod returns the call if n calls a method of the given name
+func calledMethod(n ast.Node, name string) (*ast.CallExpr, bool) {
+	call, ok := n.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	return call, ok && selector.Sel.Name == name
+}
+
+// stringLiteral returns the value of a string literal expression
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// TestOpenAPIMatchesResponses plays part of a game through the JSON API and checks
+// every request and response body against the document served at /api/openapi.json.
+func TestOpenAPIMatchesResponses(t *testing.T) {
+	c := newSpecClient(t)
+
+	// Starting the game rolls once: a white sum of 7 and every colored die in play
+	game.DiceSource = dice.NewScripted([]int{3, 4, 1, 2, 5, 6})
+	t.Cleanup(func() { game.DiceSource = nil })
+
+	var alice, bob api.JoinResponse
+	c.call("POST", "/api/v1/games", "/api/v1/games", "", `{"name": "alice"}`, &alice)
+	code := alice.GameCode
+	gamePath := "/api/v1/games/" + code
+	c.call("POST", gamePath+"/players", "/api/v1/games/{gameCode}/players", "", `{"name": "bob"}`, &bob)
+
+	c.call("POST", gamePath+"/start", "/api/v1/games/{gameCode}/start", bob.Token, "", nil)
+	c.call("POST", gamePath+"/start", "/api/v1/games/{gameCode}/start", alice.Token, "", nil)
+	var state api.Snapshot
+	c.call("GET", gamePath, "/api/v1/games/{gameCode}", bob.Token, "", &state)
+	if state.Dice.WhiteSum() != 7 {
+		t.Fatalf("expected the scripted roll, got %+v", state.Dice)
+	}
+	c.call("POST", gamePath+"/roll", "/api/v1/games/{gameCode}/roll", alice.Token, "", nil)
+	c.call("POST", gamePath+"/end-turn", "/api/v1/games/{gameCode}/end-turn", bob.Token, "", nil)
+
+	var moves api.MovesResponse
+	c.call("GET", gamePath+"/moves", "/api/v1/games/{gameCode}/moves", alice.Token, "", &moves)
+	if len(moves.Moves) == 0 {
+		t.Fatal("expected alice to have moves after the first roll")
+	}
+	move := moves.Moves[0]
+	c.call("POST", gamePath+"/marks", "/api/v1/games/{gameCode}/marks", alice.Token,
+		fmt.Sprintf(`{"color": %q, "number": %d, "type": %q}`, move.Color, move.Number, move.Type), nil)
+	c.call("POST", gamePath+"/marks", "/api/v1/games/{gameCode}/marks", bob.Token,
+		`{"color": "red", "number": 99, "type": "white"}`, nil)
+	c.call("POST", gamePath+"/pass", "/api/v1/games/{gameCode}/pass", bob.Token, "", nil)
+	c.call("POST", gamePath+"/end-turn", "/api/v1/games/{gameCode}/end-turn", alice.Token, "", nil)
+	c.call("GET", "/api/v1/games/NOPE0", "/api/v1/games/{gameCode}", alice.Token, "", nil)
+	c.call("GET", gamePath+"/fairness", "/api/v1/games/{gameCode}/fairness", "", "", nil)
+	c.call("POST", "/api/v1/games", "/api/v1/games", "", `{"name": "carol", "client_seed": "not a seed!"}`, nil)
+
+	c.call("GET", "/api/v1/admin/janitor", "/api/v1/admin/janitor", "", "", nil)
+	adminToken = "admin-secret"
+	t.Cleanup(func() { adminToken = "" })
+	c.call("GET", "/api/v1/admin/janitor", "/api/v1/admin/janitor", alice.Token, "", nil)
+	c.call("POST", "/api/v1/admin/janitor/run", "/api/v1/admin/janitor/run", adminToken, "", nil)
+	var status janitor.Status
+	c.call("GET", "/api/v1/admin/janitor", "/api/v1/admin/janitor", adminToken, "", &status)
+	if status.LastRun == nil {
+		t.Error("expected the janitor status to include the run")
+	}
+
+	c.call("GET", "/api/v1/admin/backups", "/api/v1/admin/backups", adminToken, "", nil)
+	var snapshot backup.File
+	c.call("POST", "/api/v1/admin/backups", "/api/v1/admin/backups", adminToken, "", &snapshot)
+	var backups backup.Inventory
+	c.call("GET", "/api/v1/admin/backups", "/api/v1/admin/backups", adminToken, "", &backups)
+	if len(backups.Snapshots) != 1 || backups.Snapshots[0].Name != snapshot.Name {
+		t.Errorf("expected the snapshot %q to be listed, got %+v", snapshot.Name, backups.Snapshots)
+	}
+}
+
+type specClient struct {
+	t      *testing.T
+	server *httptest.Server
+	spec   map[string]any
+}
+
+// newSpecClient starts the server on a fresh database and fetches its OpenAPI document
+func newSpecClient(t *testing.T) *specClient {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(dir) })
+
+	err = db.InitDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	mux := http.NewServeMux()
+	add_routes(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/api/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	c := &specClient{t: t, server: server}
+	err = json.NewDecoder(resp.Body).Decode(&c.spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// call sends a request and checks its body and the response against the operation for the route.
+// The response is decoded into out when it is not nil.
+func (c *specClient) call(method, path, route, token, body string, out any) {
+	c.t.Helper()
+
+	op, ok := c.lookup("paths", route, strings.ToLower(method)).(map[string]any)
+	if !ok {
+		c.t.Fatalf("%s %s is not in the OpenAPI document", method, route)
+	}
+
+	if body != "" {
+		schema, ok := c.lookup(op, "requestBody", "content", "application/json", "schema").(map[string]any)
+		if !ok {
+			c.t.Fatalf("%s %s has no JSON request body in the OpenAPI document", method, route)
+		}
+		err := c.validate(schema, decode(c.t, []byte(body)), "request")
+		if err != nil {
+			c.t.Fatalf("%s %s: %s", method, route, err)
+		}
+	}
+
+	req, err := http.NewRequest(method, c.server.URL+path, strings.NewReader(body))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	// Successful responses must use the documented status; errors fall under the default response
+	responses, _ := op["responses"].(map[string]any)
+	response, ok := responses[strconv.Itoa(resp.StatusCode)].(map[string]any)
+	if !ok && resp.StatusCode >= 400 {
+		response, ok = responses["default"].(map[string]any)
+	}
+	if !ok {
+		c.t.Fatalf("%s %s answered %d, which the OpenAPI document doesn't describe: %s", method, route, resp.StatusCode, data)
+	}
+
+	schema, ok := c.lookup(response, "content", "application/json", "schema").(map[string]any)
+	if !ok {
+		c.t.Fatalf("%s %s answered %d without a JSON schema in the OpenAPI document", method, route, resp.StatusCode)
+	}
+	err = c.validate(schema, decode(c.t, data), "response")
+	if err != nil {
+		c.t.Fatalf("%s %s answered %d: %s\n%s", method, route, resp.StatusCode, err, data)
+	}
+
+	if out != nil {
+		err = json.Unmarshal(data, out)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+	}
+}
+
+// lookup follows keys through nested JSON objects, starting at the document for a first key that is a string
+func (c *specClient) lookup(start any, keys ...string) any {
+	var value any = c.spec
+	if m, ok := start.(map[string]any); ok {
+		value = m
+	} else {
+		keys = append([]string{start.(string)}, keys...)
+	}
+
+	for _, key := range keys {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+// validate checks a decoded JSON value against the subset of JSON schema used by OpenAPI.
+// Objects may not have properties the schema doesn't mention.
+func (c *specClient) validate(schema map[string]any, value any, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		resolved, ok := c.lookup("components", "schemas", name).(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: unknown schema %s", at, ref)
+		}
+		return c.validate(resolved, value, at)
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %v", at, value)
+		}
+
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing required property %q", at, name)
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]any)
+		additional, _ := schema["additionalProperties"].(map[string]any)
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, ok := properties[key].(map[string]any)
+			if !ok {
+				property = additional
+			}
+			if property == nil {
+				return fmt.Errorf("%s: property %q is not in the schema", at, key)
+			}
+			err := c.validate(property, object[key], at+"."+key)
+			if err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %v", at, value)
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range array {
+			err := c.validate(items, item, fmt.Sprintf("%s[%d]", at, i))
+			if err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected a string, got %v", at, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected a boolean, got %v", at, value)
+		}
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: expected a number, got %v", at, value)
+		}
+		if _, err := number.Int64(); err != nil && schema["type"] == "integer" {
+			return fmt.Errorf("%s: expected an integer, got %v", at, value)
+		}
+	default:
+		return fmt.Errorf("%s: schema without a type", at)
+	}
+	return nil
+}
+
+func decode(t *testing.T, data []byte) any {
+	t.Helper()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	err := decoder.Decode(&value)
+	if err != nil {
+		t.Fatalf("invalid JSON %q: %s", data, err)
+	}
+	return value
+}
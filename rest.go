@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"seesharpsi/stixx_online/api"
+	"seesharpsi/stixx_online/backup"
+	"seesharpsi/stixx_online/bot"
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/dice"
+	"seesharpsi/stixx_online/game"
+)
+
+// JSON API under /api/v1/.
+// Every response is JSON: one of the api types on success, an api.ErrorResponse on failure.
+
+// GetOpenAPI serves the OpenAPI document describing every route
+func GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	log.Printf("got /api/openapi.json request\n")
+	writeJSON(w, http.StatusOK, OpenAPI(Routes))
+}
+
+func APICreateGame(w http.ResponseWriter, r *http.Request) {
+	log.Printf("got /api/v1/games request\n")
+
+	var req api.PlayerRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		writeAPIError(w, http.StatusBadRequest, api.CodeBadRequest, "name is required")
+		return
+	}
+	if req.ClientSeed != "" {
+		err := dice.CheckClientSeed(req.ClientSeed)
+		if err != nil {
+			writeGameError(w, err)
+			return
+		}
+	}
+
+	gameData, err := db.CreateGame()
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	joinAndRespond(w, gameData.GameCode, req, http.StatusCreated)
+}
+
+func APIJoinGame(w http.ResponseWriter, r *http.Request) {
+	gameCode := r.PathValue("gameCode")
+	log.Printf("got /api/v1/games/%s/players request\n", gameCode)
+
+	var req api.PlayerRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		writeAPIError(w, http.StatusBadRequest, api.CodeBadRequest, "name is required")
+		return
+	}
+
+	joinAndRespond(w, gameCode, req, http.StatusOK)
+}
+
+func APIStartGame(w http.ResponseWriter, r *http.Request) {
+	session, gameData, ok := apiGame(w, r)
+	if !ok {
+		return
+	}
+
+	err := startGame(session.PlayerID, gameData.ID)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	writeSnapshot(w, gameData.GameCode, session.PlayerID)
+}
+
+func APIGetGame(w http.ResponseWriter, r *http.Request) {
+	session, gameData, ok := apiGame(w, r)
+	if !ok {
+		return
+	}
+
+	writeSnapshot(w, gameData.GameCode, session.PlayerID)
+}
+
+func APIGetMoves(w http.ResponseWriter, r *http.Request) {
+	session, gameData, ok := apiGame(w, r)
+	if !ok {
+		return
+	}
+
+	gameState, err := game.LoadGameState(gameData.GameCode)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, api.MovesResponse{Moves: gameState.Snapshot(session.PlayerID).PossibleMoves})
+}
+
+// APIGetFairness returns a game's dice commitment and rolls, with the server seed once the game has finished.
+// It needs no session, so anyone can check a game.
+func APIGetFairness(w http.ResponseWriter, r *http.Request) {
+	gameCode := r.PathValue("gameCode")
+	log.Printf("got /api/v1/games/%s/fairness request\n", gameCode)
+
+	fairness, err := game.CheckFairness(gameCode)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, fairness)
+}
+
+func APIRollDice(w http.ResponseWriter, r *http.Request) {
+	session, gameData, ok := apiGame(w, r)
+	if !ok {
+		return
+	}
+
+	err := game.RollDice(session.PlayerID, gameData.ID)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	writeSnapshot(w, gameData.GameCode, session.PlayerID)
+}
+
+func APIMakeMark(w http.ResponseWriter, r *http.Request) {
+	session, gameData, ok := apiGame(w, r)
+	if !ok {
+		return
+	}
+
+	var req api.MarkRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	err := game.MakeMark(session.PlayerID, req.Color, req.Number, gameData.ID, req.Type)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	writeSnapshot(w, gameData.GameCode, session.PlayerID)
+}
+
+func APIPassWhite(w http.ResponseWriter, r *http.Request) {
+	session, gameData, ok := apiGame(w, r)
+	if !ok {
+		return
+	}
+
+	err := game.PassWhite(session.PlayerID, gameData.ID)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	writeSnapshot(w, gameData.GameCode, session.PlayerID)
+}
+
+func APIEndTurn(w http.ResponseWriter, r *http.Request) {
+	session, gameData, ok := apiGame(w, r)
+	if !ok {
+		return
+	}
+
+	err := game.EndTurn(session.PlayerID, gameData.ID)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	writeSnapshot(w, gameData.GameCode, session.PlayerID)
+}
+
+// API helper functions
+
+// apiGame checks that the request has a session for the game in its path and loads the game
+func apiGame(w http.ResponseWriter, r *http.Request) (*Session, *db.Game, bool) {
+	gameCode := r.PathValue("gameCode")
+	log.Printf("got %s %s request\n", r.Method, r.URL.Path)
+
+	session := getSession(w, r)
+	if session == nil || session.GameCode != gameCode {
+		writeAPIError(w, http.StatusUnauthorized, api.CodeUnauthorized, "no session for this game")
+		return nil, nil, false
+	}
+
+	gameData, err := db.GetGame(gameCode)
+	if err != nil {
+		writeGameError(w, err)
+		return nil, nil, false
+	}
+
+	return session, gameData, true
+}
+
+// joinAndRespond adds a player to a game and replies with their session
+func joinAndRespond(w http.ResponseWriter, gameCode string, req api.PlayerRequest, status int) {
+	player, err := db.JoinGame(gameCode, req.Name, req.ClientSeed, game.MaxPlayers)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	token, err := newSession(w, player.ID, gameCode)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	writeJSON(w, status, api.JoinResponse{GameCode: gameCode, PlayerID: player.ID, Token: token})
+}
+
+func writeSnapshot(w http.ResponseWriter, gameCode string, playerID int) {
+	gameState, err := game.LoadGameState(gameCode)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, gameState.Snapshot(playerID))
+}
+
+// readJSON decodes a request body, replying with an error if it isn't valid.
+// Unknown fields are rejected so clients notice when they don't match the API.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, api.CodeBadRequest, "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeGameError replies with the code and status of an error from package db or game
+func writeGameError(w http.ResponseWriter, err error) {
+	code, status, message := publicError(err)
+	writeAPIError(w, status, code, message)
+}
+
+// publicError classifies an error for clients. Internal errors are logged and only described
+// as such, so database details never reach clients.
+func publicError(err error) (code string, status int, message string) {
+	code, status = classify(err)
+	if code == api.CodeInternal {
+		log.Printf("api error: %s\n", err)
+		return code, status, "internal server error"
+	}
+	return code, status, err.Error()
+}
+
+// errorCodes maps the errors of packages db and game to API error codes and HTTP statuses
+var errorCodes = []struct {
+	err    error
+	code   string
+	status int
+}{
+	{db.ErrConflict, api.CodeConflict, http.StatusConflict},
+	{db.ErrGameNotFound, api.CodeGameNotFound, http.StatusNotFound},
+	{db.ErrAlreadyStarted, api.CodeAlreadyStarted, http.StatusConflict},
+	{db.ErrGameFull, api.CodeGameFull, http.StatusConflict},
+	{db.ErrBotSeat, api.CodeBotSeat, http.StatusConflict},
+	{bot.ErrUnknownStrategy, api.CodeUnknownStrategy, http.StatusBadRequest},
+	{dice.ErrInvalidClientSeed, api.CodeInvalidClientSeed, http.StatusBadRequest},
+	{backup.ErrUnknownSnapshot, api.CodeUnknownSnapshot, http.StatusNotFound},
+	{backup.ErrUnsupported, api.CodeBackupUnsupported, http.StatusNotImplemented},
+	{game.ErrNotHost, api.CodeNotHost, http.StatusForbidden},
+	{game.ErrTooFewPlayers, api.CodeTooFewPlayers, http.StatusConflict},
+	{game.ErrNotStarted, api.CodeNotStarted, http.StatusConflict},
+	{game.ErrGameFinished, api.CodeGameFinished, http.StatusConflict},
+	{game.ErrNotYourTurn, api.CodeNotYourTurn, http.StatusConflict},
+	{game.ErrNotRolled, api.CodeNotRolled, http.StatusConflict},
+	{game.ErrAlreadyRolled, api.CodeAlreadyRolled, http.StatusConflict},
+	{game.ErrUnknownPlayer, api.CodeUnknownPlayer, http.StatusForbidden},
+	{game.ErrWhiteUsed, api.CodeWhiteUsed, http.StatusConflict},
+	{game.ErrColoredUsed, api.CodeColoredUsed, http.StatusConflict},
+	{game.ErrTurnDone, api.CodeTurnDone, http.StatusConflict},
+	{game.ErrWhiteFirst, api.CodeWhiteFirst, http.StatusConflict},
+	{game.ErrNotActivePlayer, api.CodeNotActivePlayer, http.StatusConflict},
+	{game.ErrInvalidMove, api.CodeInvalidMove, http.StatusUnprocessableEntity},
+	{game.ErrUnknownMoveType, api.CodeUnknownMoveType, http.StatusBadRequest},
+}
+
+// classify returns the API error code and HTTP status for an error from package db or game.
+// Errors it doesn't know are internal errors.
+func classify(err error) (string, int) {
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return e.code, e.status
+		}
+	}
+	return api.CodeInternal, http.StatusInternalServerError
+}
+
+func writeAPIError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, api.ErrorResponse{Error: api.Error{Code: code, Message: message}})
+}
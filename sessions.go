@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"seesharpsi/stixx_online/db"
+)
+
+const (
+	sessionCookie = "session"
+	sessionTTL    = 30 * 24 * time.Hour // how long a session lasts without being rotated
+	sessionRotate = 24 * time.Hour      // how old a token gets before it is replaced
+)
+
+// secureCookies sets the Secure attribute on session cookies
+var secureCookies = true
+
+// Session management
+type Session struct {
+	PlayerID int
+	GameCode string
+}
+
+// newSession stores a new session for a player, sets its cookie and returns its token
+func newSession(w http.ResponseWriter, playerID int, gameCode string) (string, error) {
+	token, tokenHash, err := generateSessionToken()
+	if err != nil {
+		return "", err
+	}
+
+	created := time.Now().UTC().Truncate(time.Second)
+	err = db.CreateSession(&db.Session{
+		TokenHash: tokenHash,
+		PlayerID:  playerID,
+		GameCode:  gameCode,
+		CreatedAt: created,
+		ExpiresAt: created.Add(sessionTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	setSessionCookie(w, token, created.Add(sessionTTL))
+	return token, nil
+}
+
+// getSession looks up the session for a request, rotating its token once it gets old.
+// API clients can send the token as "Authorization: Bearer <token>" instead of the cookie;
+// those tokens aren't rotated since the client never sees the new cookie.
+func getSession(w http.ResponseWriter, r *http.Request) *Session {
+	token, bearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !bearer {
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil {
+			return nil
+		}
+		token = cookie.Value
+	}
+
+	stored, err := db.GetSession(hashSessionToken(token))
+	if err != nil {
+		return nil
+	}
+
+	if !bearer && time.Since(stored.CreatedAt) > sessionRotate {
+		token, tokenHash, err := generateSessionToken()
+		if err == nil {
+			oldTokenHash := stored.TokenHash
+			stored.TokenHash = tokenHash
+			stored.CreatedAt = time.Now().UTC().Truncate(time.Second)
+			stored.ExpiresAt = stored.CreatedAt.Add(sessionTTL)
+			if db.RotateSession(oldTokenHash, stored) == nil {
+				setSessionCookie(w, token, stored.ExpiresAt)
+			}
+		}
+	}
+
+	return &Session{
+		PlayerID: stored.PlayerID,
+		GameCode: stored.GameCode,
+	}
+}
+
+// clearSession deletes the request's session and its cookie
+func clearSession(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err == nil {
+		db.DeleteSession(hashSessionToken(cookie.Value))
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func setSessionCookie(w http.ResponseWriter, token string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// generateSessionToken returns a random 128-bit token and the hash it is stored under
+func generateSessionToken() (string, string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", "", err
+	}
+
+	token := hex.EncodeToString(b)
+	return token, hashSessionToken(token), nil
+}
+
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,86 @@
+// Server-Sent Events extension for htmx 2.
+//
+// Supports the attributes of the official htmx SSE extension that this site uses:
+//   sse-connect="/url"         opens an EventSource for the element and its children
+//   sse-swap="name1,name2"     swaps the data of those events into the element (hx-swap picks the style)
+//   hx-trigger="sse:name"      triggers the element's request when the event arrives
+(function () {
+	"use strict";
+
+	htmx.defineExtension("sse", {
+		onEvent: function (name, evt) {
+			var elt = evt.target || (evt.detail && evt.detail.elt);
+			if (!elt || !elt.hasAttribute) {
+				return;
+			}
+
+			if (name === "htmx:afterProcessNode" && elt.hasAttribute("sse-connect")) {
+				connect(elt);
+			} else if (name === "htmx:beforeCleanupElement" && elt.sseSource) {
+				elt.sseSource.close();
+				elt.sseSource = null;
+			}
+		}
+	});
+
+	function connect(root) {
+		if (root.sseSource) {
+			return;
+		}
+
+		var source = new EventSource(root.getAttribute("sse-connect"));
+		root.sseSource = source;
+
+		var listening = {};
+		eventNames(root).forEach(function (name) {
+			if (listening[name]) {
+				return;
+			}
+			listening[name] = true;
+			source.addEventListener(name, function (e) {
+				handle(root, name, e.data);
+			});
+		});
+	}
+
+	// eventNames collects every event name used by sse-swap and hx-trigger inside root
+	function eventNames(root) {
+		var names = [];
+		each(root, "[sse-swap]", function (elt) {
+			names = names.concat(swapNames(elt));
+		});
+		each(root, "[hx-trigger*='sse:']", function (elt) {
+			var re = /sse:([\w-]+)/g;
+			var match;
+			while ((match = re.exec(elt.getAttribute("hx-trigger"))) !== null) {
+				names.push(match[1]);
+			}
+		});
+		return names;
+	}
+
+	function handle(root, name, data) {
+		each(root, "[sse-swap]", function (elt) {
+			if (swapNames(elt).indexOf(name) >= 0) {
+				htmx.swap(elt, data, { swapStyle: elt.getAttribute("hx-swap") || "innerHTML" });
+			}
+		});
+		each(root, "[hx-trigger*='sse:" + name + "']", function (elt) {
+			htmx.trigger(elt, "sse:" + name);
+		});
+	}
+
+	function swapNames(elt) {
+		return elt.getAttribute("sse-swap").split(",").map(function (name) {
+			return name.trim();
+		});
+	}
+
+	// each calls fn for root and its descendants matching selector
+	function each(root, selector, fn) {
+		if (root.matches(selector)) {
+			fn(root);
+		}
+		root.querySelectorAll(selector).forEach(fn);
+	}
+})();
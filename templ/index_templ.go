@@ -29,7 +29,7 @@ func Index() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<!doctype html><html lang=\"en\"><head><title>Qwixx Online</title><meta charset=\"UTF-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1\"><link rel=\"stylesheet\" type=\"text/css\" href=\"/static/styles.css\"><script type=\"text/javascript\" src=\"/static/htmx.min.js\"></script><style>\n\t\t\t\tbody {\n\t\t\t\t\tfont-family: Arial, Helvetica, sans-serif;\n\t\t\t\t\tbackground-color: #f0f0f0;\n\t\t\t\t\tdisplay: flex;\n\t\t\t\t\tjustify-content: center;\n\t\t\t\t\talign-items: center;\n\t\t\t\t\tmin-height: 100vh;\n\t\t\t\t\tmargin: 0;\n\t\t\t\t}\n\t\t\t\t.container {\n\t\t\t\t\tbackground-color: white;\n\t\t\t\t\tpadding: 2rem;\n\t\t\t\t\tborder-radius: 10px;\n\t\t\t\t\tbox-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);\n\t\t\t\t\tmax-width: 500px;\n\t\t\t\t\twidth: 100%;\n\t\t\t\t}\n\t\t\t\th1 {\n\t\t\t\t\ttext-align: center;\n\t\t\t\t\tcolor: #333;\n\t\t\t\t\tmargin-bottom: 2rem;\n\t\t\t\t}\n\t\t\t\t.game-options {\n\t\t\t\t\tdisplay: flex;\n\t\t\t\t\tgap: 2rem;\n\t\t\t\t\tmargin-bottom: 2rem;\n\t\t\t\t}\n\t\t\t\t.option {\n\t\t\t\t\tflex: 1;\n\t\t\t\t\ttext-align: center;\n\t\t\t\t}\n\t\t\t\tbutton {\n\t\t\t\t\tbackground-color: #4CAF50;\n\t\t\t\t\tcolor: white;\n\t\t\t\t\tpadding: 12px 24px;\n\t\t\t\t\tborder: none;\n\t\t\t\t\tborder-radius: 5px;\n\t\t\t\t\tcursor: pointer;\n\t\t\t\t\tfont-size: 16px;\n\t\t\t\t\twidth: 100%;\n\t\t\t\t\ttransition: background-color 0.3s;\n\t\t\t\t}\n\t\t\t\tbutton:hover {\n\t\t\t\t\tbackground-color: #45a049;\n\t\t\t\t}\n\t\t\t\t.form-container {\n\t\t\t\t\tdisplay: none;\n\t\t\t\t\tmargin-top: 2rem;\n\t\t\t\t}\n\t\t\t\t.form-container.active {\n\t\t\t\t\tdisplay: block;\n\t\t\t\t}\n\t\t\t\t.form-group {\n\t\t\t\t\tmargin-bottom: 1rem;\n\t\t\t\t}\n\t\t\t\tlabel {\n\t\t\t\t\tdisplay: block;\n\t\t\t\t\tmargin-bottom: 0.5rem;\n\t\t\t\t\tfont-weight: bold;\n\t\t\t\t\tcolor: #555;\n\t\t\t\t}\n\t\t\t\tinput[type=\"text\"] {\n\t\t\t\t\twidth: 100%;\n\t\t\t\t\tpadding: 10px;\n\t\t\t\t\tborder: 1px solid #ddd;\n\t\t\t\t\tborder-radius: 5px;\n\t\t\t\t\tfont-size: 16px;\n\t\t\t\t\tbox-sizing: border-box;\n\t\t\t\t}\n\t\t\t\tinput[type=\"text\"]:focus {\n\t\t\t\t\toutline: none;\n\t\t\t\t\tborder-color: #4CAF50;\n\t\t\t\t}\n\t\t\t\t.error {\n\t\t\t\t\tcolor: #f44336;\n\t\t\t\t\tfont-size: 14px;\n\t\t\t\t\tmargin-top: 0.5rem;\n\t\t\t\t}\n\t\t\t\t.success {\n\t\t\t\t\tcolor: #4CAF50;\n\t\t\t\t\tfont-size: 14px;\n\t\t\t\t\tmargin-top: 0.5rem;\n\t\t\t\t}\n\t\t\t\t.back-button {\n\t\t\t\t\tbackground-color: #888;\n\t\t\t\t\tmargin-top: 1rem;\n\t\t\t\t}\n\t\t\t\t.back-button:hover {\n\t\t\t\t\tbackground-color: #666;\n\t\t\t\t}\n\t\t\t</style></head><body><div class=\"container\"><h1>Qwixx Online</h1><div id=\"main-menu\"><div class=\"game-options\"><div class=\"option\"><button onclick=\"showCreateForm()\">Create New Game</button></div><div class=\"option\"><button onclick=\"showJoinForm()\">Join Game</button></div></div></div><div id=\"create-form\" class=\"form-container\"><h2>Create New Game</h2><form hx-post=\"/create-game\" hx-target=\"#game-response\" hx-swap=\"innerHTML\"><div class=\"form-group\"><label for=\"creator-name\">Your Name:</label> <input type=\"text\" id=\"creator-name\" name=\"name\" required></div><button type=\"submit\">Create Game</button></form><button class=\"back-button\" onclick=\"showMainMenu()\">Back</button><div id=\"game-response\"></div></div><div id=\"join-form\" class=\"form-container\"><h2>Join Existing Game</h2><form hx-post=\"/join-game\" hx-target=\"#join-response\" hx-swap=\"innerHTML\"><div class=\"form-group\"><label for=\"player-name\">Your Name:</label> <input type=\"text\" id=\"player-name\" name=\"name\" required></div><div class=\"form-group\"><label for=\"game-code\">Game Code:</label> <input type=\"text\" id=\"game-code\" name=\"gameCode\" required placeholder=\"Enter 5-character code\" maxlength=\"5\" style=\"text-transform: uppercase;\"></div><button type=\"submit\">Join Game</button></form><button class=\"back-button\" onclick=\"showMainMenu()\">Back</button><div id=\"join-response\"></div></div></div><div id=\"instructions\" style=\"margin-top: 3rem; padding: 2rem; background-color: #f9f9f9; border-radius: 8px;\"><h2 style=\"text-align: center; margin-bottom: 1.5rem;\">How to Play Qwixx</h2><div style=\"max-width: 600px; margin: 0 auto;\"><p><strong>Objective:</strong> Mark off as many numbers as possible in the four colored rows to score the most points.</p><h3>Game Setup</h3><ul><li>2-4 players can play</li><li>Each player has 4 colored rows: Red (2-12), Yellow (2-12), Green (12-2), Blue (12-2)</li><li>6 dice are used: 2 white dice and 4 colored dice</li></ul><h3>How to Play</h3><ul><li>On each turn, the active player rolls all 6 dice</li><li><strong>All players</strong> can mark the sum of the two white dice in any color row</li><li><strong>Only the active player</strong> can also mark the sum of one white die + one colored die in the matching color row</li><li>Numbers must be marked from left to right - you can't go back!</li><li>To lock a row (mark the last number), you need at least 5 marks in that row</li></ul><h3>Game End</h3><p>The game ends when either:</p><ul><li>2 rows are locked (marked with the rightmost number)</li><li>A player has 4 penalties</li></ul><h3>Scoring</h3><p>Points increase with more marks: 1 mark = 1 point, 2 = 3 points, 3 = 6 points, and so on up to 12 marks = 78 points. Each penalty costs 5 points.</p></div></div><script>\n\t\t\t\tfunction showCreateForm() {\n\t\t\t\t\tdocument.getElementById('main-menu').style.display = 'none';\n\t\t\t\t\tdocument.getElementById('create-form').classList.add('active');\n\t\t\t\t\tdocument.getElementById('join-form').classList.remove('active');\n\t\t\t\t}\n\n\t\t\t\tfunction showJoinForm() {\n\t\t\t\t\tdocument.getElementById('main-menu').style.display = 'none';\n\t\t\t\t\tdocument.getElementById('join-form').classList.add('active');\n\t\t\t\t\tdocument.getElementById('create-form').classList.remove('active');\n\t\t\t\t}\n\n\t\t\t\tfunction showMainMenu() {\n\t\t\t\t\tdocument.getElementById('main-menu').style.display = 'block';\n\t\t\t\t\tdocument.getElementById('create-form').classList.remove('active');\n\t\t\t\t\tdocument.getElementById('join-form').classList.remove('active');\n\t\t\t\t\tdocument.getElementById('game-response').innerHTML = '';\n\t\t\t\t\tdocument.getElementById('join-response').innerHTML = '';\n\t\t\t\t}\n\n\t\t\t\t// Auto-uppercase game code input\n\t\t\t\tdocument.getElementById('game-code').addEventListener('input', function(e) {\n\t\t\t\t\te.target.value = e.target.value.toUpperCase();\n\t\t\t\t});\n\t\t\t</script></body></html>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<!doctype html><html lang=\"en\"><head><title>Qwixx Online</title><meta charset=\"UTF-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1\"><link rel=\"stylesheet\" type=\"text/css\" href=\"/static/styles.css\"><script type=\"text/javascript\" src=\"/static/htmx.min.js\"></script><style>\n\t\t\t\tbody {\n\t\t\t\t\tfont-family: Arial, Helvetica, sans-serif;\n\t\t\t\t\tbackground-color: #f0f0f0;\n\t\t\t\t\tdisplay: flex;\n\t\t\t\t\tjustify-content: center;\n\t\t\t\t\talign-items: center;\n\t\t\t\t\tmin-height: 100vh;\n\t\t\t\t\tmargin: 0;\n\t\t\t\t}\n\t\t\t\t.container {\n\t\t\t\t\tbackground-color: white;\n\t\t\t\t\tpadding: 2rem;\n\t\t\t\t\tborder-radius: 10px;\n\t\t\t\t\tbox-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);\n\t\t\t\t\tmax-width: 500px;\n\t\t\t\t\twidth: 100%;\n\t\t\t\t}\n\t\t\t\th1 {\n\t\t\t\t\ttext-align: center;\n\t\t\t\t\tcolor: #333;\n\t\t\t\t\tmargin-bottom: 2rem;\n\t\t\t\t}\n\t\t\t\t.game-options {\n\t\t\t\t\tdisplay: flex;\n\t\t\t\t\tgap: 2rem;\n\t\t\t\t\tmargin-bottom: 2rem;\n\t\t\t\t}\n\t\t\t\t.option {\n\t\t\t\t\tflex: 1;\n\t\t\t\t\ttext-align: center;\n\t\t\t\t}\n\t\t\t\tbutton {\n\t\t\t\t\tbackground-color: #4CAF50;\n\t\t\t\t\tcolor: white;\n\t\t\t\t\tpadding: 12px 24px;\n\t\t\t\t\tborder: none;\n\t\t\t\t\tborder-radius: 5px;\n\t\t\t\t\tcursor: pointer;\n\t\t\t\t\tfont-size: 16px;\n\t\t\t\t\twidth: 100%;\n\t\t\t\t\ttransition: background-color 0.3s;\n\t\t\t\t}\n\t\t\t\tbutton:hover {\n\t\t\t\t\tbackground-color: #45a049;\n\t\t\t\t}\n\t\t\t\t.form-container {\n\t\t\t\t\tdisplay: none;\n\t\t\t\t\tmargin-top: 2rem;\n\t\t\t\t}\n\t\t\t\t.form-container.active {\n\t\t\t\t\tdisplay: block;\n\t\t\t\t}\n\t\t\t\t.form-group {\n\t\t\t\t\tmargin-bottom: 1rem;\n\t\t\t\t}\n\t\t\t\tlabel {\n\t\t\t\t\tdisplay: block;\n\t\t\t\t\tmargin-bottom: 0.5rem;\n\t\t\t\t\tfont-weight: bold;\n\t\t\t\t\tcolor: #555;\n\t\t\t\t}\n\t\t\t\tinput[type=\"text\"] {\n\t\t\t\t\twidth: 100%;\n\t\t\t\t\tpadding: 10px;\n\t\t\t\t\tborder: 1px solid #ddd;\n\t\t\t\t\tborder-radius: 5px;\n\t\t\t\t\tfont-size: 16px;\n\t\t\t\t\tbox-sizing: border-box;\n\t\t\t\t}\n\t\t\t\tinput[type=\"text\"]:focus {\n\t\t\t\t\toutline: none;\n\t\t\t\t\tborder-color: #4CAF50;\n\t\t\t\t}\n\t\t\t\t.error {\n\t\t\t\t\tcolor: #f44336;\n\t\t\t\t\tfont-size: 14px;\n\t\t\t\t\tmargin-top: 0.5rem;\n\t\t\t\t}\n\t\t\t\t.success {\n\t\t\t\t\tcolor: #4CAF50;\n\t\t\t\t\tfont-size: 14px;\n\t\t\t\t\tmargin-top: 0.5rem;\n\t\t\t\t}\n\t\t\t\t.back-button {\n\t\t\t\t\tbackground-color: #888;\n\t\t\t\t\tmargin-top: 1rem;\n\t\t\t\t}\n\t\t\t\t.back-button:hover {\n\t\t\t\t\tbackground-color: #666;\n\t\t\t\t}\n\t\t\t</style></head><body><div class=\"container\"><h1>Qwixx Online</h1><div id=\"main-menu\"><div class=\"game-options\"><div class=\"option\"><button onclick=\"showCreateForm()\">Create New Game</button></div><div class=\"option\"><button onclick=\"showJoinForm()\">Join Game</button></div></div></div><div id=\"create-form\" class=\"form-container\"><h2>Create New Game</h2><form hx-post=\"/create-game\" hx-target=\"#game-response\" hx-swap=\"innerHTML\"><div class=\"form-group\"><label for=\"creator-name\">Your Name:</label> <input type=\"text\" id=\"creator-name\" name=\"name\" required></div><div class=\"form-group\"><label for=\"creator-seed\">Client Seed:</label> <input type=\"text\" id=\"creator-seed\" name=\"clientSeed\" class=\"client-seed\" required placeholder=\"Any word, mixed into the dice\" maxlength=\"64\" pattern=\"[A-Za-z0-9_\\-]+\"></div><button type=\"submit\">Create Game</button></form><button class=\"back-button\" onclick=\"showMainMenu()\">Back</button><div id=\"game-response\"></div></div><div id=\"join-form\" class=\"form-container\"><h2>Join Existing Game</h2><form hx-post=\"/join-game\" hx-target=\"#join-response\" hx-swap=\"innerHTML\"><div class=\"form-group\"><label for=\"player-name\">Your Name:</label> <input type=\"text\" id=\"player-name\" name=\"name\" required></div><div class=\"form-group\"><label for=\"game-code\">Game Code:</label> <input type=\"text\" id=\"game-code\" name=\"gameCode\" required placeholder=\"Enter 5-character code\" maxlength=\"5\" style=\"text-transform: uppercase;\"></div><div class=\"form-group\"><label for=\"player-seed\">Client Seed:</label> <input type=\"text\" id=\"player-seed\" name=\"clientSeed\" class=\"client-seed\" required placeholder=\"Any word, mixed into the dice\" maxlength=\"64\" pattern=\"[A-Za-z0-9_\\-]+\"></div><button type=\"submit\">Join Game</button></form><button class=\"back-button\" onclick=\"showMainMenu()\">Back</button><div id=\"join-response\"></div></div></div><div id=\"instructions\" style=\"margin-top: 3rem; padding: 2rem; background-color: #f9f9f9; border-radius: 8px;\"><h2 style=\"text-align: center; margin-bottom: 1.5rem;\">How to Play Qwixx</h2><div style=\"max-width: 600px; margin: 0 auto;\"><p><strong>Objective:</strong> Mark off as many numbers as possible in the four colored rows to score the most points.</p><h3>Game Setup</h3><ul><li>2-4 players can play</li><li>Each player has 4 colored rows: Red (2-12), Yellow (2-12), Green (12-2), Blue (12-2)</li><li>6 dice are used: 2 white dice and 4 colored dice</li></ul><h3>How to Play</h3><ul><li>On each turn, the active player rolls all 6 dice</li><li><strong>All players</strong> can mark the sum of the two white dice in any color row</li><li><strong>Only the active player</strong> can also mark the sum of one white die + one colored die in the matching color row</li><li>Numbers must be marked from left to right - you can't go back!</li><li>To lock a row (mark the last number), you need at least 5 marks in that row</li></ul><h3>Game End</h3><p>The game ends when either:</p><ul><li>2 rows are locked (marked with the rightmost number)</li><li>A player has 4 penalties</li></ul><h3>Scoring</h3><p>Points increase with more marks: 1 mark = 1 point, 2 = 3 points, 3 = 6 points, and so on up to 12 marks = 78 points. Each penalty costs 5 points.</p></div></div><script>\n\t\t\t\tfunction showCreateForm() {\n\t\t\t\t\tdocument.getElementById('main-menu').style.display = 'none';\n\t\t\t\t\tdocument.getElementById('create-form').classList.add('active');\n\t\t\t\t\tdocument.getElementById('join-form').classList.remove('active');\n\t\t\t\t}\n\n\t\t\t\tfunction showJoinForm() {\n\t\t\t\t\tdocument.getElementById('main-menu').style.display = 'none';\n\t\t\t\t\tdocument.getElementById('join-form').classList.add('active');\n\t\t\t\t\tdocument.getElementById('create-form').classList.remove('active');\n\t\t\t\t}\n\n\t\t\t\tfunction showMainMenu() {\n\t\t\t\t\tdocument.getElementById('main-menu').style.display = 'block';\n\t\t\t\t\tdocument.getElementById('create-form').classList.remove('active');\n\t\t\t\t\tdocument.getElementById('join-form').classList.remove('active');\n\t\t\t\t\tdocument.getElementById('game-response').innerHTML = '';\n\t\t\t\t\tdocument.getElementById('join-response').innerHTML = '';\n\t\t\t\t}\n\n\t\t\t\t// Start every client seed out random, so the dice get the player's randomness even if they keep it\n\t\t\t\tdocument.querySelectorAll('.client-seed').forEach(function(input) {\n\t\t\t\t\tvar bytes = new Uint8Array(16);\n\t\t\t\t\tcrypto.getRandomValues(bytes);\n\t\t\t\t\tinput.value = Array.from(bytes, function(b) { return b.toString(16).padStart(2, '0'); }).join('');\n\t\t\t\t});\n\n\t\t\t\t// Auto-uppercase game code input\n\t\t\t\tdocument.getElementById('game-code').addEventListener('input', function(e) {\n\t\t\t\t\te.target.value = e.target.value.toUpperCase();\n\t\t\t\t});\n\t\t\t</script></body></html>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"seesharpsi/stixx_online/api"
+	"seesharpsi/stixx_online/db"
+	"seesharpsi/stixx_online/events"
+	"seesharpsi/stixx_online/game"
+)
+
+// GameSocket lets a player follow and play a game over a WebSocket using the messages in package api.
+// A client reconnecting with ?since=<seq> first receives the events it missed, when the server still has them.
+func GameSocket(w http.ResponseWriter, r *http.Request) {
+	gameCode := r.PathValue("gameCode")
+	log.Printf("got /ws/game/%s request\n", gameCode)
+
+	// Get session
+	session := getSession(w, r)
+	if session == nil || session.GameCode != gameCode {
+		writeAPIError(w, http.StatusUnauthorized, api.CodeUnauthorized, "no session for this game")
+		return
+	}
+
+	// Get game
+	gameData, err := db.GetGame(gameCode)
+	if err != nil {
+		writeGameError(w, err)
+		return
+	}
+
+	since := -1
+	if s := r.URL.Query().Get("since"); s != "" {
+		since, err = strconv.Atoi(s)
+		if err != nil || since < 0 {
+			writeAPIError(w, http.StatusBadRequest, api.CodeBadRequest, "since must be a sequence number")
+			return
+		}
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		log.Printf("error accepting websocket for %s: %s\n", gameCode, err)
+		return
+	}
+	defer conn.CloseNow()
+
+	// Subscribe before looking at the history so nothing falls in between
+	updates, unsubscribe := events.Subscribe(gameData.ID)
+	defer unsubscribe()
+
+	sock := &gameSocket{
+		conn:     conn,
+		ctx:      r.Context(),
+		gameID:   gameData.ID,
+		gameCode: gameCode,
+		playerID: session.PlayerID,
+	}
+
+	if since >= 0 {
+		missed, ok := events.Since(gameData.ID, since)
+		if ok {
+			sock.seq = since
+			for _, ev := range missed {
+				err := sock.sendEvent(ev)
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	err = sock.sendSnapshot("")
+	if err != nil {
+		return
+	}
+
+	messages := make(chan api.ClientMessage)
+	go sock.read(messages)
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			err = sock.handle(msg)
+		case ev := <-updates:
+			err = sock.sendEvent(ev)
+			// Send the state once everything already queued has been sent
+			for err == nil && len(updates) > 0 {
+				err = sock.sendEvent(<-updates)
+			}
+			if err == nil {
+				err = sock.sendSnapshot("")
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// gameSocket is one player's WebSocket connection to a game
+type gameSocket struct {
+	conn     *websocket.Conn
+	ctx      context.Context
+	gameID   int
+	gameCode string
+	playerID int
+	seq      int // last event sent to the client
+}
+
+// read passes the client's messages on until the connection closes
+func (s *gameSocket) read(messages chan<- api.ClientMessage) {
+	defer close(messages)
+	for {
+		var msg api.ClientMessage
+		err := wsjson.Read(s.ctx, s.conn, &msg)
+		if err != nil {
+			return
+		}
+
+		select {
+		case messages <- msg:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// handle carries out a client's message and replies to it.
+// Changes to the game reach every client, including this one, as events.
+func (s *gameSocket) handle(msg api.ClientMessage) error {
+	var err error
+	switch msg.Type {
+	case api.MsgRoll:
+		err = game.RollDice(s.playerID, s.gameID)
+	case api.MsgMark:
+		err = game.MakeMark(s.playerID, msg.Color, msg.Number, s.gameID, msg.Move)
+	case api.MsgPass:
+		err = game.PassWhite(s.playerID, s.gameID)
+	case api.MsgEndTurn:
+		err = game.EndTurn(s.playerID, s.gameID)
+	case api.MsgSnapshot:
+		return s.sendSnapshot(msg.ID)
+	default:
+		return s.send(api.ServerMessage{Type: api.MsgError, ID: msg.ID, Code: api.CodeBadRequest, Error: "unknown message type"})
+	}
+
+	if err != nil {
+		code, _, message := publicError(err)
+		return s.send(api.ServerMessage{Type: api.MsgError, ID: msg.ID, Code: code, Error: message})
+	}
+	return s.send(api.ServerMessage{Type: api.MsgOK, ID: msg.ID})
+}
+
+func (s *gameSocket) sendEvent(ev events.Event) error {
+	// Events replayed on connect can also arrive from the subscription
+	if ev.Seq <= s.seq {
+		return nil
+	}
+	s.seq = ev.Seq
+	return s.send(api.ServerMessage{Type: api.MsgEvent, Seq: ev.Seq, Event: &ev})
+}
+
+func (s *gameSocket) sendSnapshot(id string) error {
+	seq := events.Latest(s.gameID)
+	gameState, err := game.LoadGameState(s.gameCode)
+	if err != nil {
+		return s.send(api.ServerMessage{Type: api.MsgError, ID: id, Code: api.CodeInternal, Error: "Failed to load game"})
+	}
+
+	snapshot := gameState.Snapshot(s.playerID)
+	return s.send(api.ServerMessage{Type: api.MsgSnapshot, ID: id, Seq: seq, State: &snapshot})
+}
+
+func (s *gameSocket) send(msg api.ServerMessage) error {
+	return wsjson.Write(s.ctx, s.conn, msg)
+}